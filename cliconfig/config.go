@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/ContainX/depcon/marathon"
 	"github.com/ContainX/depcon/pkg/userdir"
 	"io"
 	"os"
@@ -21,6 +22,8 @@ const (
 	TypeMarathon   = "marathon"
 	TypeKubernetes = "kubernetes"
 	TypeECS        = "ecs"
+	// Environment variable which overrides the role within the configuration
+	RoleEnvVar = "DEPCON_ROLE"
 )
 
 var (
@@ -49,12 +52,29 @@ type ConfigFile struct {
 	RootService  bool                          `json:"rootservice"`
 	Environments map[string]*ConfigEnvironment `json:"environments,omitempty"`
 	DefaultEnv   string                        `json:"default,omitempty"`
-	filename     string                        // not serialized
+	// The role of the user (eg. developer, operator) used to restrict actions within protected environments
+	Role     string      `json:"role,omitempty"`
+	Lint     *LintConfig `json:"lint,omitempty"`
+	filename string      // not serialized
+}
+
+// Settings for descriptor linting
+type LintConfig struct {
+	// Ids of the rules which are not checked
+	Disabled []string `json:"disabled,omitempty"`
 }
 
 type ConfigEnvironment struct {
 	// currently only supporting marathon as initial release
 	Marathon *ServiceConfig `json:"marathon,omitempty"`
+	// ${PARAMS} substitution values specific to this environment
+	Params map[string]string `json:"params,omitempty"`
+	// Periods during which changes to this environment are refused
+	Freezes []*FreezeWindow `json:"freezes,omitempty"`
+	// Limits applications and groups must satisfy before they are deployed to this environment
+	Guardrails *marathon.Guardrails `json:"guardrails,omitempty"`
+	// Policy files (json or yaml) evaluated before deploying to this environment
+	Policies []string `json:"policies,omitempty"`
 }
 
 type ServiceConfig struct {
@@ -223,6 +243,15 @@ func (configFile *ConfigFile) GetEnvironment(name string) (*ConfigEnvironment, e
 	return configEnv, nil
 }
 
+// Returns the role of the current user.  The DEPCON_ROLE environment variable takes precedence over
+// the role within the configuration
+func (configFile *ConfigFile) CurrentRole() string {
+	if role := os.Getenv(RoleEnvVar); role != "" {
+		return role
+	}
+	return configFile.Role
+}
+
 func (configFile *ConfigFile) GetEnvironments() []string {
 	keys := make([]string, 0, len(configFile.Environments))
 	for k := range configFile.Environments {
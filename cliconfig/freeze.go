@@ -0,0 +1,193 @@
+package cliconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	freezeDateFormat = "2006-01-02"
+)
+
+var (
+	ErrorInvalidFreeze = errors.New("Freeze windows require a cron expression and/or a start or end date")
+)
+
+// A period during which changes to an environment are not allowed
+type FreezeWindow struct {
+	// Cron style expression (minute hour day-of-month month day-of-week).  The freeze is active during
+	// every minute the expression matches.  eg. "* 16-23 * * 5" freezes Friday afternoons and evenings
+	Cron string `json:"cron,omitempty"`
+	// Start of the freeze as a date (2006-01-02) or RFC3339 timestamp
+	Start string `json:"start,omitempty"`
+	// End of the freeze as a date (inclusive) or RFC3339 timestamp
+	End string `json:"end,omitempty"`
+	// Time zone the window is evaluated in (eg. America/New_York).  Defaults to local time
+	Timezone string `json:"timezone,omitempty"`
+	// Shown to anyone attempting a change during the freeze
+	Reason string `json:"reason,omitempty"`
+}
+
+// Returns the first freeze window which is active at {t} or nil
+func (configEnv *ConfigEnvironment) ActiveFreeze(t time.Time) (*FreezeWindow, error) {
+	for _, w := range configEnv.Freezes {
+		active, err := w.Active(t)
+		if err != nil {
+			return nil, err
+		}
+		if active {
+			return w, nil
+		}
+	}
+	return nil, nil
+}
+
+// Returns true if the freeze is in effect at {t}.  When both a cron expression and a date range are
+// specified the cron expression only applies within the range
+func (w *FreezeWindow) Active(t time.Time) (bool, error) {
+	if w.Cron == "" && w.Start == "" && w.End == "" {
+		return false, ErrorInvalidFreeze
+	}
+
+	loc := time.Local
+	if w.Timezone != "" {
+		l, err := time.LoadLocation(w.Timezone)
+		if err != nil {
+			return false, fmt.Errorf("Invalid freeze timezone '%s': %s", w.Timezone, err.Error())
+		}
+		loc = l
+	}
+	t = t.In(loc)
+
+	if w.Start != "" {
+		start, _, err := parseFreezeTime(w.Start, loc)
+		if err != nil {
+			return false, err
+		}
+		if t.Before(start) {
+			return false, nil
+		}
+	}
+	if w.End != "" {
+		end, isDate, err := parseFreezeTime(w.End, loc)
+		if err != nil {
+			return false, err
+		}
+		if isDate {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !t.Before(end) {
+			return false, nil
+		}
+	}
+	if w.Cron != "" {
+		return cronMatches(w.Cron, t)
+	}
+	return true, nil
+}
+
+func (w *FreezeWindow) String() string {
+	parts := []string{}
+	if w.Start != "" || w.End != "" {
+		parts = append(parts, fmt.Sprintf("%s to %s", orDefault(w.Start, "*"), orDefault(w.End, "*")))
+	}
+	if w.Cron != "" {
+		parts = append(parts, fmt.Sprintf("cron '%s'", w.Cron))
+	}
+	if w.Timezone != "" {
+		parts = append(parts, w.Timezone)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Parses a date or RFC3339 timestamp.  Returns true if {s} was a date
+func parseFreezeTime(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(freezeDateFormat, s, loc); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, false, fmt.Errorf("Invalid freeze date '%s', must be 2006-01-02 or RFC3339", s)
+	}
+	return t, false, nil
+}
+
+// The allowed range of each cron field: minute, hour, day-of-month, month, day-of-week
+var cronBounds = [][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// Returns true if the 5 field cron {expr} matches the minute of {t}.  As with cron, when both day-of-month
+// and day-of-week are restricted a match of either is sufficient
+func cronMatches(expr string, t time.Time) (bool, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronBounds) {
+		return false, fmt.Errorf("Invalid cron expression '%s', expected 5 fields", expr)
+	}
+
+	values := []int{t.Minute(), t.Hour(), t.Day(), int(t.Month()), int(t.Weekday())}
+	matches := make([]bool, len(fields))
+	for i, f := range fields {
+		m, err := cronFieldMatches(f, values[i], cronBounds[i][0], cronBounds[i][1])
+		if err != nil {
+			return false, fmt.Errorf("Invalid cron expression '%s': %s", expr, err.Error())
+		}
+		// 7 is also Sunday
+		if i == 4 && !m && values[i] == 0 {
+			m, _ = cronFieldMatches(f, 7, cronBounds[i][0], cronBounds[i][1])
+		}
+		matches[i] = m
+	}
+
+	day := matches[2] && matches[4]
+	if fields[2] != "*" && fields[4] != "*" {
+		day = matches[2] || matches[4]
+	}
+	return matches[0] && matches[1] && matches[3] && day, nil
+}
+
+// Returns true if {v} matches the cron {field} which is a list of values, ranges (a-b) and steps (*/n, a-b/n)
+func cronFieldMatches(field string, v, min, max int) (bool, error) {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return false, fmt.Errorf("invalid step '%s'", part)
+			}
+			step, part = s, part[:idx]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return false, fmt.Errorf("invalid value '%s'", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return false, fmt.Errorf("invalid range '%s'", part)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return false, fmt.Errorf("'%s' is out of range %d-%d", part, min, max)
+		}
+		if v >= lo && v <= hi && (v-lo)%step == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
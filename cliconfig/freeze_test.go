@@ -0,0 +1,67 @@
+package cliconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreezeDateRange(t *testing.T) {
+	w := &FreezeWindow{Start: "2017-12-20", End: "2018-01-02", Timezone: "UTC"}
+
+	active, err := w.Active(time.Date(2018, 1, 2, 23, 59, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	active, _ = w.Active(time.Date(2018, 1, 3, 0, 0, 0, 0, time.UTC))
+	assert.False(t, active)
+
+	active, _ = w.Active(time.Date(2017, 12, 19, 23, 59, 0, 0, time.UTC))
+	assert.False(t, active)
+}
+
+func TestFreezeCron(t *testing.T) {
+	// Friday from 16:00 onwards
+	w := &FreezeWindow{Cron: "* 16-23 * * 5", Timezone: "UTC"}
+
+	active, err := w.Active(time.Date(2017, 6, 2, 16, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	active, _ = w.Active(time.Date(2017, 6, 2, 15, 59, 0, 0, time.UTC))
+	assert.False(t, active)
+
+	active, _ = w.Active(time.Date(2017, 6, 3, 16, 30, 0, 0, time.UTC))
+	assert.False(t, active)
+}
+
+func TestCronFields(t *testing.T) {
+	sunday := time.Date(2017, 6, 4, 10, 15, 0, 0, time.UTC)
+
+	m, _ := cronMatches("*/15 * * * 7", sunday)
+	assert.True(t, m)
+	m, _ = cronMatches("0,30 * * * *", sunday)
+	assert.False(t, m)
+	// day-of-month or day-of-week when both are restricted
+	m, _ = cronMatches("* * 1 * 0", sunday)
+	assert.True(t, m)
+
+	_, err := cronMatches("* * *", sunday)
+	assert.Error(t, err)
+	_, err = cronMatches("* 25 * * *", sunday)
+	assert.Error(t, err)
+}
+
+func TestActiveFreeze(t *testing.T) {
+	env := &ConfigEnvironment{Freezes: []*FreezeWindow{
+		{Start: "2017-01-01", End: "2017-01-02", Reason: "new year"},
+		{Cron: "* * * * *", Reason: "always"},
+	}}
+	w, err := env.ActiveFreeze(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "always", w.Reason)
+
+	_, err = (&ConfigEnvironment{Freezes: []*FreezeWindow{{Reason: "empty"}}}).ActiveFreeze(time.Now())
+	assert.Equal(t, ErrorInvalidFreeze, err)
+}
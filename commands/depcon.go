@@ -128,6 +128,7 @@ func executeWithExistingConfig() {
 		os.Exit(1)
 	} else {
 		viper.Set(ViperEnv, envName)
+		marathon.Version = Version
 		if configFile.RootService {
 			marathon.AddJailedMarathonToCmd(rootCmd, configFile)
 		} else {
@@ -135,7 +136,7 @@ func executeWithExistingConfig() {
 		}
 	}
 	compose.AddComposeToCmd(rootCmd, nil)
-	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(configCmd, historyCmd, schemaCmd)
 	rootCmd.Execute()
 }
 
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"github.com/ContainX/depcon/cliconfig"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/pkg/journal"
+	"github.com/spf13/cobra"
+)
+
+const (
+	T_HISTORY = `
+{{ "TIMESTAMP" }}	{{ "ENV" }}	{{ "USER" }}	{{ "ACTION" }}	{{ "ID" }}	{{ "OUTCOME" }}	{{ "ELAPSED" }}	{{ "DEPLOYMENT" }}
+{{ range . }}{{ .Timestamp.Local.Format "2006-01-02 15:04:05" }}	{{ .Env }}	{{ .User }}	{{ .Action }}	{{ .ID }}	{{ .Outcome }}	{{ .Elapsed }}	{{ .DeploymentID }}
+{{end}}`
+
+	APP_FLAG   = "app"
+	LIMIT_FLAG = "limit"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Shows the changes made by depcon from the local journal",
+	Long: `Every create, update, scale, restart, destroy, blue/green deployment and rollback performed by depcon is
+recorded within a local journal in the configuration directory.  This lists the recorded changes, optionally
+filtered by app or group id (including anything beneath it) and by environment when -e/--env is specified.
+
+Use -o json to ship the history to a log pipeline.
+
+    eg. depcon -e prod history --app /product --limit 20`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filter := &journal.Filter{}
+		filter.ID, _ = cmd.Flags().GetString(APP_FLAG)
+		filter.Limit, _ = cmd.Flags().GetInt(LIMIT_FLAG)
+		if rootCmd.PersistentFlags().Changed(FlagEnv) {
+			filter.Env, _ = rootCmd.PersistentFlags().GetString(FlagEnv)
+		}
+
+		entries, err := journal.New(cliconfig.ConfigDir()).Query(filter)
+		cli.Output(templateFor(T_HISTORY, entries), err)
+	},
+}
+
+func init() {
+	historyCmd.Flags().String(APP_FLAG, "", "Only show changes to this app or group id (including apps beneath it)")
+	historyCmd.Flags().Int(LIMIT_FLAG, 0, "Only show the most recent number of changes (0 for all)")
+}
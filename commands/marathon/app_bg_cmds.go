@@ -1,13 +1,13 @@
 package marathon
 
 import (
-	"os"
 	"strings"
 	"time"
 
 	"github.com/ContainX/depcon/marathon/bluegreen"
 	"github.com/ContainX/depcon/pkg/cli"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 const (
@@ -42,6 +42,7 @@ func init() {
                   eg. -p MYVAR=value would replace ${MYVAR} with "value" in the application file.
                   These take precidence over env vars`)
 	bgCmd.Flags().Bool(BG_DRYRUN_FLAG, false, "Dry run (no deployment or scaling)")
+	addLockFlags(bgCmd)
 
 }
 
@@ -49,15 +50,34 @@ func deployBlueGreenCmd(cmd *cobra.Command, args []string) {
 	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
 		return
 	}
-	a, err := bgc(cmd).DeployBlueGreenFromFile(args[0])
+	dryrun, _ := cmd.Flags().GetBool(BG_DRYRUN_FLAG)
+	if !dryrun {
+		if err := guardFreeze(client(cmd)); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	release := func() {}
+	if id := descriptorFileID(args[0]); id != "" && !dryrun {
+		var err error
+		if release, err = acquireDeployLocks(cmd, client(cmd), []string{id}); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	start := time.Now()
+	a, err := bgc(cmd, args[0]).DeployBlueGreenFromFile(args[0])
+	release()
+	if !dryrun {
+		journalAction(client(cmd), ActionBlueGreen, appID(a, args[0]), fileHash(args[0]), start, err)
+	}
 	if err != nil {
-		cli.Output(nil, err)
-		os.Exit(1)
+		exitWithError(err)
 	}
 	cli.Output(templateFor(T_APPLICATION, a), err)
 }
 
-func bgc(c *cobra.Command) bluegreen.BlueGreen {
+func bgc(c *cobra.Command, filename string) bluegreen.BlueGreen {
 
 	paramsFile, _ := c.Flags().GetString(ENV_FILE_FLAG)
 	params, _ := c.Flags().GetStringSlice(PARAMS_FLAG)
@@ -74,6 +94,13 @@ func bgc(c *cobra.Command) bluegreen.BlueGreen {
 	opts.StepDelay = time.Duration(sd) * time.Second
 	opts.ProxyWaitTimeout = time.Duration(lbtimeout) * time.Second
 	opts.DryRun, _ = c.Flags().GetBool(BG_DRYRUN_FLAG)
+	opts.Metadata = deployMetadata(viper.GetString(ENV_NAME), filename)
+	verifier, err := policyVerifier(viper.GetString(ENV_NAME))
+	if err != nil {
+		exitWithError(err)
+	}
+	opts.Verifier = verifier
+	opts.Strict = viper.GetBool(STRICT_PARSE_FLAG)
 
 	if paramsFile != "" {
 		envParams, _ := parseParamsFile(paramsFile)
@@ -91,5 +118,5 @@ func bgc(c *cobra.Command) bluegreen.BlueGreen {
 		}
 	}
 
-	return bluegreen.NewBlueGreenClient(client(c), opts)
+	return bluegreen.NewBlueGreenClient(unjournaled(client(c)), opts)
 }
@@ -21,6 +21,9 @@ const (
 	TEMPLATE_CTX_FLAG = "tempctx"
 	DEFAULT_CTX       = "template-context.json"
 	STOP_DEPLOYS_FLAG = "stop-deploys"
+	HEALTHY_FLAG      = "healthy"
+	READY_FLAG        = "ready"
+	SHOW_SECRETS_FLAG = "show-secrets"
 )
 
 var appCmd = &cobra.Command{
@@ -78,6 +81,9 @@ var appGetCmd = &cobra.Command{
 			return
 		}
 		v, e := client(cmd).GetApplication(args[0])
+		if show, _ := cmd.Flags().GetBool(SHOW_SECRETS_FLAG); !show {
+			v = v.MaskSecrets()
+		}
 		cli.Output(templateFor(templateFormat(T_APPLICATION, cmd), v), e)
 	},
 }
@@ -128,25 +134,46 @@ var appRollbackCmd = &cobra.Command{
 	Run:   rollbackAppVersion,
 }
 
+var appWaitCmd = &cobra.Command{
+	Use:   "wait [applicationId]",
+	Short: "Waits for any deployments of [applicationId] to complete",
+	Long: `Waits for any deployments of [applicationId] to complete.  Useful after out-of-band changes made to the application.
+
+    Use --healthy and/or --ready to additionally wait until all tasks pass their health and/or readiness checks`,
+	Run: waitForApp,
+}
+
 var appConvertFileCmd = &cobra.Command{
 	Use:   "convert [from.(json | yaml)] [to.(json | yaml)]",
 	Short: "Utilty to convert an application file from json to yaml or yaml to json.",
-	Run:   convertFile,
+	Long: `Converts an application file from json to yaml or yaml to json.
+
+    Multi-document YAML files (documents separated by '---') may contain both applications and groups.
+    When converting them to json each document is written to its own file (eg. apps-1.json, apps-2.json)`,
+	Run: convertFile,
 }
 
 func init() {
 	appUpdateCmd.AddCommand(appUpdateCPUCmd, appUpdateMemoryCmd)
-	appCmd.AddCommand(appListCmd, appGetCmd, logCmd, appCreateCmd, appUpdateCmd, appDestroyCmd, appRollbackCmd, bgCmd, appRestartCmd, appScaleCmd, appPauseCmd, appVersionsCmd, appConvertFileCmd)
+	appCmd.AddCommand(appListCmd, appGetCmd, logCmd, appCreateCmd, appUpdateCmd, appDestroyCmd, appRollbackCmd, bgCmd, appRestartCmd, appScaleCmd, appPauseCmd, appVersionsCmd, appConvertFileCmd, appWaitCmd, appWhyCmd, appPromoteCmd, appExportCmd)
 
 	// Create Flags
 	addDeployCreateFlags(appCreateCmd)
 
 	appListCmd.Flags().String(FORMAT_FLAG, "", "Custom output format. Example: '{{range .Apps}}{{ .Container.Docker.Image }}{{end}}'")
 	appGetCmd.Flags().String(FORMAT_FLAG, "", "Custom output format. Example: '{{ .ID }}'")
+	appGetCmd.Flags().Bool(SHOW_SECRETS_FLAG, false, "Show the values of sensitive environment variables instead of masking them")
 	applyCommonAppFlags(appUpdateCPUCmd, appUpdateMemoryCmd, appRollbackCmd, appDestroyCmd, appRestartCmd, appScaleCmd, appPauseCmd)
+	addConfirmFlags(appDestroyCmd)
+	addConfirmFlags(appScaleCmd)
+
+	appWaitCmd.Flags().Bool(HEALTHY_FLAG, false, "Wait until all tasks are passing their health checks")
+	appWaitCmd.Flags().Bool(READY_FLAG, false, "Wait until all tasks are passing their readiness checks")
+	appWaitCmd.Flags().DurationP(TIMEOUT_FLAG, "t", marathon.DefaultTimeout, "Max duration to wait (ex. 90s | 2m)")
 }
 
 func exitWithError(err error) {
+	runExitHooks()
 	cli.Output(nil, err)
 	os.Exit(1)
 }
@@ -190,6 +217,7 @@ func destroyApp(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	confirmDestructive(cmd, ActionDestroy, args[0], false)
 	v, e := client(cmd).DestroyApplication(args[0])
 	cli.Output(templateFor(T_DEPLOYMENT_ID, v), e)
 	waitForDeploymentIfFlagged(cmd, v.DeploymentID)
@@ -205,6 +233,9 @@ func scaleApp(cmd *cobra.Command, args []string) {
 		cli.Output(nil, err)
 		os.Exit(1)
 	}
+	if instances == 0 {
+		confirmDestructive(cmd, ActionScale, args[0], false)
+	}
 	v, e := client(cmd).ScaleApplication(args[0], instances)
 	cli.Output(templateFor(T_DEPLOYMENT_ID, v), e)
 	waitForDeploymentIfFlagged(cmd, v.DeploymentID)
@@ -275,15 +306,33 @@ func rollbackAppVersion(cmd *cobra.Command, args []string) {
 	cli.Output(templateFor(T_APPLICATION, v), e)
 }
 
+func waitForApp(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		os.Exit(1)
+	}
+
+	healthy, _ := cmd.Flags().GetBool(HEALTHY_FLAG)
+	ready, _ := cmd.Flags().GetBool(READY_FLAG)
+	timeout, _ := cmd.Flags().GetDuration(TIMEOUT_FLAG)
+
+	opts := &marathon.WaitOptions{Healthy: healthy, Ready: ready}
+	if err := client(cmd).WaitForApplicationWithOptions(args[0], timeout, opts); err != nil {
+		exitWithError(err)
+	}
+	v, e := client(cmd).GetApplication(args[0])
+	cli.Output(templateFor(T_APPLICATION, v), e)
+}
+
 func convertFile(cmd *cobra.Command, args []string) {
 	if cli.EvalPrintUsage(Usage(cmd), args, 2) {
 		os.Exit(1)
 	}
-	if err := encoding.ConvertFile(args[0], args[1], &marathon.Application{}); err != nil {
+	written, err := encoding.ConvertDocuments(args[0], args[1], descriptorValue)
+	if err != nil {
 		cli.Output(nil, err)
 		os.Exit(1)
 	}
-	fmt.Printf("Source file %s has been re-written into new format in %s\n\n", args[0], args[1])
+	fmt.Printf("Source file %s has been re-written into new format in %s\n\n", args[0], strings.Join(written, ", "))
 }
 
 func waitForDeploymentIfFlagged(cmd *cobra.Command, depId string) {
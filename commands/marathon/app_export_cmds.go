@@ -0,0 +1,256 @@
+package marathon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/ContainX/depcon/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	TEMPLATE_FLAG = "template"
+	APP_KEY_FLAG  = "key"
+
+	// Template context keys of the extracted environment specific values
+	CtxImage     = "image"
+	CtxImageTag  = "imageTag"
+	CtxInstances = "instances"
+	CtxCpus      = "cpus"
+	CtxMem       = "mem"
+)
+
+var appExportCmd = &cobra.Command{
+	Use:   "export [applicationId] [file.(json | yaml)]",
+	Short: "Exports the live [applicationId] as a descriptor, optionally as a parameterized template",
+	Long: `Exports the live definition of [applicationId] with runtime fields removed.  If no file is specified
+the descriptor is written to stdout as JSON.
+
+With --template the environment specific values (image tag, instances, cpus, mem and any --env-keys) are
+moved into the template context under environments.[env].apps.[key] and replaced in the descriptor with
+{{ }} placeholders.  Values are read from each of the --envs (default: the current environment) and
+merged into the --tempctx file, keeping any values already declared there.
+
+    eg. depcon mar app export /product/api api.json --template --envs qa,prod --env-keys DB_HOST,LOG_LEVEL`,
+	Run: exportApp,
+}
+
+func init() {
+	appExportCmd.Flags().Bool(TEMPLATE_FLAG, false, "Emit a parameterized template and add its values to the template context")
+	appExportCmd.Flags().StringSlice(ENVS_FLAG, nil, "Environments to read template values from (default: the current environment)")
+	appExportCmd.Flags().StringSlice(ENV_KEYS_FLAG, nil, "Env vars to move into the template context (eg. --env-keys DB_HOST,LOG_LEVEL)")
+	appExportCmd.Flags().String(TEMPLATE_CTX_FLAG, DEFAULT_CTX, "The template context file to merge values into")
+	appExportCmd.Flags().String(APP_KEY_FLAG, "", "The app key within the template context (default: the app id with '/' replaced by '-')")
+}
+
+func exportApp(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+	id := args[0]
+
+	et := encoding.JSON
+	if len(args) > 1 {
+		var err error
+		if et, err = encoding.EncoderTypeFromExt(args[1]); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	var descriptor string
+	if tmpl, _ := cmd.Flags().GetBool(TEMPLATE_FLAG); tmpl {
+		descriptor = exportAppTemplate(cmd, id, et)
+	} else {
+		app, err := client(cmd).GetApplication(id)
+		if err != nil {
+			exitWithError(err)
+		}
+		encoder, _ := encoding.NewEncoder(et)
+		if descriptor, err = encoder.MarshalIndent(app.Descriptor()); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	if len(args) < 2 {
+		fmt.Println(descriptor)
+		return
+	}
+	if err := ioutil.WriteFile(args[1], []byte(descriptor), 0644); err != nil {
+		exitWithError(err)
+	}
+	fmt.Printf("Application %s has been exported to %s\n", id, args[1])
+}
+
+func exportAppTemplate(cmd *cobra.Command, id string, et encoding.EncoderType) string {
+	envs, _ := cmd.Flags().GetStringSlice(ENVS_FLAG)
+	if len(envs) == 0 {
+		envs = []string{viper.GetString(ENV_NAME)}
+	}
+
+	apps := make([]*marathon.Application, len(envs))
+	for i, env := range envs {
+		app, err := envApplication(cmd, env, id)
+		if err != nil {
+			exitWithError(err)
+		}
+		apps[i] = app
+	}
+
+	key, _ := cmd.Flags().GetString(APP_KEY_FLAG)
+	if key == "" {
+		key = strings.Replace(utils.TrimRootPath(id), "/", "-", -1)
+	}
+	envKeys, _ := cmd.Flags().GetStringSlice(ENV_KEYS_FLAG)
+
+	descriptor, values, err := templateFromApps(key, apps, envKeys, et)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	ctxFile, _ := cmd.Flags().GetString(TEMPLATE_CTX_FLAG)
+	ctx, err := LoadTemplateContext(ctxFile)
+	if err != nil {
+		exitWithError(err)
+	}
+	for i, env := range envs {
+		ctx.SetAppValues(env, key, values[i])
+	}
+	if err := ctx.Save(ctxFile); err != nil {
+		exitWithError(err)
+	}
+	fmt.Fprintf(os.Stderr, "Template values for '%s' have been written to %s\n", key, ctxFile)
+	return descriptor
+}
+
+// Builds a template descriptor from {apps} (the same application within each environment) replacing the
+// environment specific values with placeholders referencing {key} within the template context.  The
+// first application is used for all other fields.  Returns the encoded template and the extracted values
+// for each of the {apps}
+func templateFromApps(key string, apps []*marathon.Application, envKeys []string, et encoding.EncoderType) (string, []map[string]interface{}, error) {
+	base := apps[0].Descriptor()
+	values := make([]map[string]interface{}, len(apps))
+	for i := range apps {
+		values[i] = map[string]interface{}{}
+	}
+
+	b, err := json.Marshal(base)
+	if err != nil {
+		return "", nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", nil, err
+	}
+
+	numeric := []string{}
+	param := func(name string, value func(app *marathon.Application) (interface{}, bool)) {
+		for i, app := range apps {
+			if v, ok := value(app); ok {
+				values[i][name] = v
+			}
+		}
+	}
+
+	param(CtxInstances, func(app *marathon.Application) (interface{}, bool) { return app.Instances, true })
+	param(CtxCpus, func(app *marathon.Application) (interface{}, bool) { return app.CPUs, true })
+	param(CtxMem, func(app *marathon.Application) (interface{}, bool) { return app.Mem, true })
+	for _, name := range []string{CtxInstances, CtxCpus, CtxMem} {
+		m[name] = placeholderToken(name)
+		numeric = append(numeric, name)
+	}
+
+	if image := dockerImage(base); image != "" {
+		if repo, _ := splitImage(image); sameRepository(apps, repo) {
+			param(CtxImageTag, func(app *marathon.Application) (interface{}, bool) {
+				_, tag := splitImage(dockerImage(app))
+				return tag, true
+			})
+			setDockerImage(m, repo+":"+placeholderToken(CtxImageTag))
+		} else {
+			param(CtxImage, func(app *marathon.Application) (interface{}, bool) { return dockerImage(app), true })
+			setDockerImage(m, placeholderToken(CtxImage))
+		}
+	}
+
+	if env, ok := m["env"].(map[string]interface{}); ok {
+		for _, k := range envKeys {
+			if ev, found := base.Env[k]; !found || ev.IsSecret() {
+				continue
+			}
+			param(k, func(app *marathon.Application) (interface{}, bool) {
+				ev, found := app.Env[k]
+				return ev.Value, found && !ev.IsSecret()
+			})
+			env[k] = placeholderToken(k)
+		}
+	}
+
+	encoder, err := encoding.NewEncoder(et)
+	if err != nil {
+		return "", nil, err
+	}
+	out, err := encoder.MarshalIndent(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, name := range numeric {
+		out = strings.Replace(out, `"`+placeholderToken(name)+`"`, placeholder(key, name), -1)
+	}
+	for _, vals := range values {
+		for name := range vals {
+			out = strings.Replace(out, placeholderToken(name), placeholder(key, name), -1)
+		}
+	}
+	return out, values, nil
+}
+
+// Returns the template expression resolving {name} of the app {key} within the template context
+func placeholder(key, name string) string {
+	return fmt.Sprintf(`{{ index . %q %q }}`, key, name)
+}
+
+// A unique token used to mark where a placeholder is inserted once the descriptor is encoded
+func placeholderToken(name string) string {
+	return "__depcon_" + name + "__"
+}
+
+func dockerImage(app *marathon.Application) string {
+	if app.Container == nil || app.Container.Docker == nil {
+		return ""
+	}
+	return app.Container.Docker.Image
+}
+
+func setDockerImage(m map[string]interface{}, image string) {
+	if container, ok := m["container"].(map[string]interface{}); ok {
+		if docker, ok := container["docker"].(map[string]interface{}); ok {
+			docker["image"] = image
+		}
+	}
+}
+
+// Splits a docker {image} into its repository and tag.  The tag defaults to latest
+func splitImage(image string) (string, string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, "latest"
+	}
+	return image[:i], image[i+1:]
+}
+
+func sameRepository(apps []*marathon.Application, repo string) bool {
+	for _, app := range apps {
+		if r, _ := splitImage(dockerImage(app)); r != repo {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package marathon
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTestApp(image string, instances int, mem float64, dbHost string) *marathon.Application {
+	return &marathon.Application{
+		ID:           "/product/api",
+		Instances:    instances,
+		CPUs:         0.5,
+		Mem:          mem,
+		Version:      "2017-06-01T10:00:00.000Z",
+		TasksRunning: instances,
+		Container:    &marathon.Container{Type: "DOCKER", Docker: &marathon.Docker{Image: image}},
+		Env:          map[string]marathon.EnvVar{"DB_HOST": marathon.EnvValue(dbHost), "DB_PASSWORD": marathon.EnvSecret("db")},
+	}
+}
+
+func TestTemplateFromApps(t *testing.T) {
+	qa := exportTestApp("registry/api:1.2.0", 1, 256, "qa-db")
+	prod := exportTestApp("registry/api:1.1.0", 4, 1024, "prod-db")
+
+	for _, et := range []encoding.EncoderType{encoding.JSON, encoding.YAML} {
+		tmpl, values, err := templateFromApps("product-api", []*marathon.Application{qa, prod}, []string{"DB_HOST", "DB_PASSWORD"}, et)
+		assert.NoError(t, err)
+		assert.Contains(t, tmpl, `registry/api:{{ index . "product-api" "imageTag" }}`)
+		assert.NotContains(t, tmpl, "__depcon_")
+		assert.Equal(t, "1.1.0", values[1]["imageTag"])
+		assert.Equal(t, 4, values[1]["instances"])
+		assert.Equal(t, "qa-db", values[0]["DB_HOST"])
+		_, found := values[0]["DB_PASSWORD"]
+		assert.False(t, found, "secrets should not be extracted")
+
+		ctx := &TemplateContext{}
+		ctx.SetAppValues("QA", "product-api", values[0])
+		ctx.SetAppValues("prod", "product-api", values[1])
+
+		dir, _ := ioutil.TempDir("", "export")
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "api.json")
+		if et == encoding.YAML {
+			file = filepath.Join(dir, "api.yaml")
+		}
+		ioutil.WriteFile(file, []byte(tmpl), 0644)
+
+		var buf bytes.Buffer
+		assert.NoError(t, ctx.TransformWithEnv(&buf, file, dir, "prod"))
+		encoder, _ := encoding.NewEncoder(et)
+		app := new(marathon.Application)
+		assert.NoError(t, encoder.UnMarshalStr(buf.String(), app))
+		assert.Equal(t, "registry/api:1.1.0", app.Container.Docker.Image)
+		assert.Equal(t, 4, app.Instances)
+		assert.Equal(t, float64(1024), app.Mem)
+		assert.Equal(t, "prod-db", app.Env["DB_HOST"].Value)
+		assert.True(t, app.Env["DB_PASSWORD"].IsSecret())
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	repo, tag := splitImage("localhost:5000/api:1.0")
+	assert.Equal(t, "localhost:5000/api", repo)
+	assert.Equal(t, "1.0", tag)
+
+	repo, tag = splitImage("localhost:5000/api")
+	assert.Equal(t, "localhost:5000/api", repo)
+	assert.Equal(t, "latest", tag)
+}
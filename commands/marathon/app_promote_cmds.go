@@ -0,0 +1,119 @@
+package marathon
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/pkg/httpclient"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FROM_ENV_FLAG = "from"
+	TO_ENV_FLAG   = "to"
+	NO_IMAGE_FLAG = "no-image"
+	LABELS_FLAG   = "labels"
+	ENV_KEYS_FLAG = "env-keys"
+)
+
+var (
+	ErrorPromoteEnvs = errors.New("Both --from and --to environments must be specified and must differ")
+)
+
+var appPromoteCmd = &cobra.Command{
+	Use:   "promote [applicationId]",
+	Short: "Promotes [applicationId] from one environment to another",
+	Long: `Reads the live [applicationId] from the --from environment and carries the container image,
+version labels and any allow-listed env keys onto the current definition within the --to environment.
+The resulting changes are shown and then deployed, waiting until the application is healthy.
+
+Labels carried default to those whose key contains "version" unless --labels is specified.
+
+    eg. depcon mar app promote /product/api --from staging --to prod --env-keys RELEASE,BUILD_NUMBER`,
+	Run: promoteApp,
+}
+
+func init() {
+	appPromoteCmd.Flags().String(FROM_ENV_FLAG, "", "The source environment to promote from")
+	appPromoteCmd.Flags().String(TO_ENV_FLAG, "", "The target environment to promote to")
+	appPromoteCmd.Flags().Bool(NO_IMAGE_FLAG, false, "Do not carry over the container image")
+	appPromoteCmd.Flags().StringSlice(LABELS_FLAG, nil, "Label keys to carry over (default: keys containing 'version')")
+	appPromoteCmd.Flags().StringSlice(ENV_KEYS_FLAG, nil, "Env keys to carry over (allow-list)")
+	appPromoteCmd.Flags().Bool(DRYRUN_FLAG, false, "Show the changes - don't actually deploy")
+	appPromoteCmd.Flags().Bool(FORCE_FLAG, false, "Force the update even if a deployment is in progress")
+	appPromoteCmd.Flags().DurationP(TIMEOUT_FLAG, "t", marathon.DefaultTimeout, "Max duration to wait for the application to become healthy (ex. 90s | 2m)")
+}
+
+func promoteApp(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+	id := args[0]
+
+	from, _ := cmd.Flags().GetString(FROM_ENV_FLAG)
+	to, _ := cmd.Flags().GetString(TO_ENV_FLAG)
+	if from == "" || to == "" || from == to {
+		exitWithError(ErrorPromoteEnvs)
+	}
+
+	source, err := envApplication(cmd, from, id)
+	if err != nil {
+		exitWithError(err)
+	}
+	targetClient, err := clientForEnv(cmd, to)
+	if err != nil {
+		exitWithError(err)
+	}
+	target, err := envApplication(cmd, to, id)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	noImage, _ := cmd.Flags().GetBool(NO_IMAGE_FLAG)
+	opts := &marathon.PromoteOptions{Image: !noImage}
+	opts.Labels, _ = cmd.Flags().GetStringSlice(LABELS_FLAG)
+	opts.EnvKeys, _ = cmd.Flags().GetStringSlice(ENV_KEYS_FLAG)
+
+	promoted := marathon.Promote(source, target, opts)
+	changes, err := marathon.Diff(promoted, target)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	cli.Output(templateFor(T_FIELD_CHANGES, changes), nil)
+	if len(changes) == 0 {
+		fmt.Printf("'%s' in %s already matches %s - nothing to promote\n", id, to, from)
+		return
+	}
+	if dryrun, _ := cmd.Flags().GetBool(DRYRUN_FLAG); dryrun {
+		return
+	}
+
+	force, _ := cmd.Flags().GetBool(FORCE_FLAG)
+	timeout, _ := cmd.Flags().GetDuration(TIMEOUT_FLAG)
+	promoted.StampMetadata(deployMetadata(to, ""))
+	if _, err := targetClient.UpdateApplication(promoted, false, force); err != nil {
+		exitWithError(err)
+	}
+	if err := targetClient.WaitForApplicationWithOptions(id, timeout, &marathon.WaitOptions{Healthy: true}); err != nil {
+		exitWithError(err)
+	}
+
+	v, e := targetClient.GetApplication(id)
+	cli.Output(templateFor(T_APPLICATION, v.MaskSecrets()), e)
+}
+
+// Fetches the live application {id} from the environment {env}
+func envApplication(cmd *cobra.Command, env, id string) (*marathon.Application, error) {
+	c, err := clientForEnv(cmd, env)
+	if err != nil {
+		return nil, err
+	}
+	app, err := c.GetApplication(id)
+	if err == httpclient.ErrorNotFound {
+		return nil, fmt.Errorf("'%s' does not exist in %s", id, env)
+	}
+	return app, err
+}
@@ -0,0 +1,110 @@
+package marathon
+
+import (
+	"fmt"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+const (
+	RESET_DELAY_FLAG = "reset-delay"
+)
+
+// Summarizes why an application is waiting in the launch queue
+type QueueDiagnosis struct {
+	AppID            string                           `json:"appId"`
+	Queued           bool                             `json:"queued"`
+	Count            int                              `json:"count"`
+	Since            string                           `json:"since,omitempty"`
+	Delay            marathon.QueueDelay              `json:"delay"`
+	DelayReset       bool                             `json:"delayReset"`
+	ProcessedOffers  int                              `json:"processedOffers"`
+	UnusedOffers     int                              `json:"unusedOffers"`
+	LastUnusedOffer  string                           `json:"lastUnusedOfferAt,omitempty"`
+	Reasons          []*DeclineReason                 `json:"declineReasons"`
+	LastUnusedOffers []*marathon.UnusedOffer          `json:"lastUnusedOffers,omitempty"`
+	LastTaskFailure  *marathon.LastTaskFailure        `json:"lastTaskFailure,omitempty"`
+	Readiness        []*marathon.ReadinessCheckResult `json:"readiness,omitempty"`
+}
+
+type DeclineReason struct {
+	Reason    string `json:"reason"`
+	Declined  int    `json:"declined"`
+	Processed int    `json:"processed"`
+	Hint      string `json:"hint"`
+}
+
+var appWhyCmd = &cobra.Command{
+	Use:   "why [applicationId]",
+	Short: "Explains why [applicationId] is not starting by examining the launch queue",
+	Long: `Fetches the launch queue entry for [applicationId] including the last unused offers and
+summarizes which resources, constraints or roles caused Mesos offers to be declined.  The last task
+failure is also shown when available.
+
+Use --reset-delay to reset the launch backoff delay so Marathon attempts to launch tasks immediately`,
+	Run: whyApp,
+}
+
+func init() {
+	appWhyCmd.Flags().Bool(RESET_DELAY_FLAG, false, "Reset the launch delay (backoff) for the application")
+}
+
+func whyApp(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+	id := args[0]
+
+	qt, err := client(cmd).GetQueuedApplication(id)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	d := &QueueDiagnosis{AppID: id, Reasons: []*DeclineReason{}}
+
+	app, err := client(cmd).GetApplicationWithEmbed(id, marathon.EmbedAppLastTaskFailure, marathon.EmbedAppReadiness)
+	if err != nil {
+		exitWithError(err)
+	}
+	d.AppID = app.ID
+	d.LastTaskFailure = app.LastTaskFailure
+	d.Readiness = app.ReadinessCheckResults
+
+	if qt != nil {
+		d.Queued = true
+		d.Count = qt.Count
+		d.Since = qt.Since
+		d.Delay = qt.Delay
+		d.LastUnusedOffers = qt.LastUnusedOffers
+
+		if s := qt.ProcessedOffersSummary; s != nil {
+			d.ProcessedOffers = s.ProcessedOffersCount
+			d.UnusedOffers = s.UnusedOffersCount
+			d.LastUnusedOffer = s.LastUnusedOfferAt
+		}
+
+		for _, r := range qt.DeclineReasons() {
+			d.Reasons = append(d.Reasons, &DeclineReason{
+				Reason:    r.Reason,
+				Declined:  r.Declined,
+				Processed: r.Processed,
+				Hint:      marathon.DeclineReasonHint(r.Reason, app),
+			})
+		}
+
+		if reset, _ := cmd.Flags().GetBool(RESET_DELAY_FLAG); reset {
+			if err := client(cmd).ResetQueueDelay(id); err != nil {
+				exitWithError(err)
+			}
+			d.DelayReset = true
+		}
+	} else {
+		if reset, _ := cmd.Flags().GetBool(RESET_DELAY_FLAG); reset {
+			fmt.Printf("'%s' is not in the launch queue, there is no delay to reset\n", id)
+		}
+	}
+
+	cli.Output(templateFor(T_QUEUE_DIAGNOSIS, d), nil)
+}
@@ -0,0 +1,298 @@
+package marathon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/pkg/httpclient"
+	"github.com/ContainX/depcon/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	PRUNE_FLAG  = "prune"
+	PREFIX_FLAG = "prefix"
+	WATCH_FLAG  = "watch"
+
+	ApplyCreate    = "create"
+	ApplyUpdate    = "update"
+	ApplyUnchanged = "unchanged"
+	ApplyPrune     = "prune"
+	ApplyFailed    = "failed"
+
+	KindApp   = "app"
+	KindGroup = "group"
+)
+
+var (
+	ErrorPrunePrefix = errors.New("--prune requires a managed --prefix (eg. --prefix /product) to limit which apps may be destroyed")
+)
+
+// The outcome of reconciling a single application or group
+type ApplyResult struct {
+	ID      string                  `json:"id"`
+	Kind    string                  `json:"kind"`
+	Action  string                  `json:"action"`
+	File    string                  `json:"file,omitempty"`
+	Changes []*marathon.FieldChange `json:"changes,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply [dir]",
+	Short: "Reconciles the cluster with the app and group descriptors within [dir]",
+	Long: `Loads every descriptor (json or yaml) within [dir], renders it through the template context and
+${PARAMS} substitution, and compares it against the live cluster.  New apps and groups are created,
+changed ones are updated and unchanged ones are left alone.
+
+Only fields declared within a descriptor are compared since Marathon fills in defaults for the rest.
+As with 'deploy create' the policies of the environment are enforced and --strict rejects unknown fields.
+
+With --prune, apps under the managed --prefix which no longer have a descriptor are destroyed.  Within
+protected environments the prefix must be confirmed (or --yes given) before the first pass.
+With --watch, the directory is re-applied on the given interval until interrupted.
+
+    eg. depcon -e prod mar apply ./marathon --prune --prefix /product --watch 5m`,
+	Run: applyDir,
+}
+
+func init() {
+	applyCmd.Flags().String(TEMPLATE_CTX_FLAG, "", "Template context file (default: [dir]/template-context.json)")
+	applyCmd.Flags().BoolP(WAIT_FLAG, "w", false, "Wait for each change to be deployed before applying the next")
+	applyCmd.Flags().Bool(PRUNE_FLAG, false, "Destroy apps under --prefix which no longer have a descriptor")
+	applyCmd.Flags().String(PREFIX_FLAG, "", "The managed app prefix which --prune is restricted to (eg. /product)")
+	applyCmd.Flags().Duration(WATCH_FLAG, time.Duration(0), "Re-apply on this interval (ex. 30s | 5m) until interrupted")
+	applyCmd.Flags().Bool(DRYRUN_FLAG, false, "Show what would change - don't actually apply anything")
+	applyCmd.Flags().BoolP(IGNORE_MISSING, "i", false, "Ignore missing ${PARAMS} that are declared in descriptors that could not be resolved")
+	applyCmd.Flags().StringP(ENV_FILE_FLAG, "c", "", "Adds a file with a param(s) that can be used for substitution")
+	applyCmd.Flags().StringSliceP(PARAMS_FLAG, "p", nil, "Adds a param(s) that can be used for substitution. eg. -p MYVAR=value")
+	addConfirmFlags(applyCmd)
+}
+
+func applyDir(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+	dir := args[0]
+
+	prune, _ := cmd.Flags().GetBool(PRUNE_FLAG)
+	prefix, _ := cmd.Flags().GetString(PREFIX_FLAG)
+	if prune && strings.Trim(prefix, "/") == "" {
+		exitWithError(ErrorPrunePrefix)
+	}
+	if dryrun, _ := cmd.Flags().GetBool(DRYRUN_FLAG); prune && !dryrun {
+		// confirmed once for everything under the prefix since --watch may prune on every pass
+		confirmDestructive(cmd, ApplyPrune, prefix, true)
+	}
+
+	interval, _ := cmd.Flags().GetDuration(WATCH_FLAG)
+	for {
+		results, err := reconcileDir(cmd, dir)
+		if err != nil {
+			if interval <= 0 {
+				exitWithError(err)
+			}
+			cli.Output(nil, err)
+		} else {
+			cli.Output(templateFor(T_APPLY_RESULTS, results), nil)
+			if interval <= 0 && hasApplyFailures(results) {
+				os.Exit(1)
+			}
+		}
+
+		if interval <= 0 {
+			return
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Loads all descriptors in {dir} and applies the differences against the live cluster
+func reconcileDir(cmd *cobra.Command, dir string) ([]*ApplyResult, error) {
+	tempctx, _ := cmd.Flags().GetString(TEMPLATE_CTX_FLAG)
+	if tempctx == "" {
+		tempctx = filepath.Join(dir, DEFAULT_CTX)
+	}
+	ctx, err := LoadTemplateContext(tempctx)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := findDescriptors(dir, tempctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// rendered and parsed the same way 'deploy create' does so policies and --strict are enforced
+	s := &deploySettings{params: envParamsFromFlags(cmd), enforcePolicies: true, ctx: ctx, rootDir: dir}
+	s.ignore, _ = cmd.Flags().GetBool(IGNORE_MISSING)
+	s.strict = viper.GetBool(STRICT_PARSE_FLAG)
+	descriptors, err := s.load(client(cmd), files, viper.GetString(ENV_NAME), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dryrun, _ := cmd.Flags().GetBool(DRYRUN_FLAG)
+	wait, _ := cmd.Flags().GetBool(WAIT_FLAG)
+
+	results := []*ApplyResult{}
+	for _, d := range descriptors {
+		r := planDescriptor(client(cmd), d)
+		if !dryrun && r.Error == "" && r.Action != ApplyUnchanged {
+			applyDescriptor(client(cmd), d, r, wait)
+		}
+		results = append(results, r)
+	}
+
+	if prune, _ := cmd.Flags().GetBool(PRUNE_FLAG); prune {
+		prefix, _ := cmd.Flags().GetString(PREFIX_FLAG)
+		pruned, err := pruneApps(client(cmd), descriptors, prefix, dryrun)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, pruned...)
+	}
+	return results, nil
+}
+
+// Compares the descriptor against the live cluster and determines the action to take
+func planDescriptor(c marathon.Marathon, d *Descriptor) *ApplyResult {
+	if d.IsApplication() {
+		r := &ApplyResult{ID: d.App.ID, Kind: KindApp, File: d.Filename}
+		live, err := c.GetApplication(d.App.ID)
+		if err != nil {
+			return planNotFound(r, err)
+		}
+		return planChanges(r, d.App, live)
+	}
+
+	r := &ApplyResult{ID: d.Group.GroupID, Kind: KindGroup, File: d.Filename}
+	live, err := c.GetGroup(d.Group.GroupID)
+	if err != nil {
+		return planNotFound(r, err)
+	}
+
+	liveApps := map[string]*marathon.Application{}
+	for _, app := range live.FlattenApps() {
+		liveApps[utils.TrimRootPath(app.ID)] = app
+	}
+
+	r.Changes = []*marathon.FieldChange{}
+	for _, app := range d.Group.FlattenApps() {
+		la, found := liveApps[utils.TrimRootPath(app.ID)]
+		if !found {
+			r.Changes = append(r.Changes, &marathon.FieldChange{Path: app.ID, Desired: ApplyCreate})
+			continue
+		}
+		changes, err := marathon.Diff(app, la)
+		if err != nil {
+			r.Action, r.Error = ApplyFailed, err.Error()
+			return r
+		}
+		for _, ch := range changes {
+			ch.Path = app.ID + ch.Path
+			r.Changes = append(r.Changes, ch)
+		}
+	}
+	r.Action = actionForChanges(r.Changes)
+	return r
+}
+
+func planNotFound(r *ApplyResult, err error) *ApplyResult {
+	if err == httpclient.ErrorNotFound {
+		r.Action = ApplyCreate
+	} else {
+		r.Action, r.Error = ApplyFailed, err.Error()
+	}
+	return r
+}
+
+func planChanges(r *ApplyResult, desired, live interface{}) *ApplyResult {
+	changes, err := marathon.Diff(desired, live)
+	if err != nil {
+		r.Action, r.Error = ApplyFailed, err.Error()
+		return r
+	}
+	r.Changes = changes
+	r.Action = actionForChanges(changes)
+	return r
+}
+
+func actionForChanges(changes []*marathon.FieldChange) string {
+	if len(changes) == 0 {
+		return ApplyUnchanged
+	}
+	return ApplyUpdate
+}
+
+func applyDescriptor(c marathon.Marathon, d *Descriptor, r *ApplyResult, wait bool) {
+	var err error
+	force := r.Action == ApplyUpdate
+	if d.IsApplication() {
+		_, err = c.CreateApplication(d.App, wait, force)
+	} else {
+		_, err = c.CreateGroup(d.Group, wait, force)
+	}
+	if err != nil {
+		r.Action, r.Error = ApplyFailed, err.Error()
+	}
+}
+
+// Destroys applications under {prefix} which are not declared by any of the {descriptors}
+func pruneApps(c marathon.Marathon, descriptors []*Descriptor, prefix string, dryrun bool) ([]*ApplyResult, error) {
+	declared := map[string]bool{}
+	for _, d := range descriptors {
+		if d.IsApplication() {
+			declared[utils.TrimRootPath(d.App.ID)] = true
+			continue
+		}
+		for _, app := range d.Group.FlattenApps() {
+			declared[utils.TrimRootPath(app.ID)] = true
+		}
+	}
+
+	apps, err := c.ListApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*ApplyResult{}
+	for _, app := range apps.Apps {
+		id := utils.TrimRootPath(app.ID)
+		if declared[id] || !underPrefix(id, prefix) {
+			continue
+		}
+		r := &ApplyResult{ID: app.ID, Kind: KindApp, Action: ApplyPrune}
+		if !dryrun {
+			if _, err := c.DestroyApplication(app.ID); err != nil {
+				r.Action, r.Error = ApplyFailed, err.Error()
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func underPrefix(id, prefix string) bool {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return false
+	}
+	id = utils.TrimRootPath(id)
+	return id == prefix || strings.HasPrefix(id, prefix+"/")
+}
+
+func hasApplyFailures(results []*ApplyResult) bool {
+	for _, r := range results {
+		if r.Action == ApplyFailed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package marathon
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/ContainX/depcon/utils"
+)
+
+var (
+	// Manifest filenames looked for at the root of a bundle
+	BundleManifests = []string{"bundle.yaml", "bundle.yml", "bundle.json"}
+
+	ErrorBundleManifest = errors.New("No bundle manifest (bundle.yaml | bundle.yml | bundle.json) was found")
+)
+
+// A release artifact containing descriptors along with the template contexts and params files used to render
+// them.  A bundle is a directory (or a .tar.gz / .tgz archive of one) with a manifest at its root:
+//
+//	name: product
+//	version: 1.4.0
+//	descriptors: [ "apps/*.yaml", "groups/backend.yaml" ]
+//	contexts: [ "template-context.json" ]
+//	params: [ "params/common.env" ]
+//
+// All paths are relative to the bundle
+type Bundle struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Descriptor files or globs.  If none are declared every descriptor within the bundle is deployed
+	Descriptors []string `json:"descriptors,omitempty"`
+	// Template contexts merged in order.  Later contexts replace the values of earlier ones
+	Contexts []string `json:"contexts,omitempty"`
+	// Params files (KEY=value per line) merged in order
+	Params []string `json:"params,omitempty"`
+
+	dir      string
+	manifest string
+	tmpDir   string
+}
+
+// Returns true if {path} is a bundle archive or a directory containing a bundle manifest
+func isBundle(path string) bool {
+	if isBundleArchive(path) {
+		return true
+	}
+	_, err := bundleManifest(path)
+	return err == nil
+}
+
+func isBundleArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// Returns the manifest within the directory {dir}
+func bundleManifest(dir string) (string, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", ErrorBundleManifest
+	}
+	for _, name := range BundleManifests {
+		f := filepath.Join(dir, name)
+		if _, err := os.Stat(f); err == nil {
+			return f, nil
+		}
+	}
+	return "", ErrorBundleManifest
+}
+
+// Opens the bundle directory or archive at {path}.  Archives are extracted into a temporary directory
+// which is removed by Close
+func openBundle(path string) (*Bundle, error) {
+	dir, tmpDir := path, ""
+	if isBundleArchive(path) {
+		var err error
+		if tmpDir, err = ioutil.TempDir("", "depcon-bundle"); err != nil {
+			return nil, err
+		}
+		if err := extractArchive(path, tmpDir); err != nil {
+			os.RemoveAll(tmpDir)
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		}
+		dir = archiveRoot(tmpDir)
+	}
+
+	manifest, err := bundleManifest(dir)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	encoder, err := encoding.NewEncoderFromFileExt(manifest)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(manifest)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+
+	b := &Bundle{dir: dir, manifest: manifest, tmpDir: tmpDir}
+	if err := encoder.UnMarshalStr(string(data), b); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("%s: %s", manifest, err.Error())
+	}
+	return b, nil
+}
+
+// Removes the extracted contents of an archived bundle
+func (b *Bundle) Close() {
+	if b.tmpDir != "" {
+		os.RemoveAll(b.tmpDir)
+	}
+}
+
+// Returns the name and version of the bundle (eg. product@1.4.0)
+func (b *Bundle) Release() string {
+	if b.Version == "" {
+		return b.Name
+	}
+	return b.Name + "@" + b.Version
+}
+
+// Returns the descriptor files of the bundle in the order declared
+func (b *Bundle) Files() ([]string, error) {
+	if len(b.Descriptors) == 0 {
+		exclude := []string{b.manifest}
+		for _, c := range b.Contexts {
+			f, err := b.path(c)
+			if err != nil {
+				return nil, err
+			}
+			exclude = append(exclude, f)
+		}
+		return findDescriptors(b.dir, exclude...)
+	}
+
+	files := []string{}
+	for _, d := range b.Descriptors {
+		pattern, err := b.path(d)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("bundle descriptor '%s' does not match any files", d)
+		}
+		for _, m := range matches {
+			if !b.contains(m) {
+				return nil, fmt.Errorf("bundle descriptor '%s' resolves outside of the bundle", d)
+			}
+			if !utils.StringInSlice(m, files) {
+				files = append(files, m)
+			}
+		}
+	}
+	return files, nil
+}
+
+// Returns the merged template contexts of the bundle
+func (b *Bundle) Context() (*TemplateContext, error) {
+	ctx := &TemplateContext{Environments: map[string]*TemplateEnvironment{}}
+	for _, c := range b.Contexts {
+		f, err := b.path(c)
+		if err != nil {
+			return nil, err
+		}
+		if !TemplateExists(f) {
+			return nil, fmt.Errorf("bundle context '%s' does not exist", c)
+		}
+		loaded, err := LoadTemplateContext(f)
+		if err != nil {
+			return nil, err
+		}
+		ctx.merge(loaded)
+	}
+	return ctx, nil
+}
+
+// Returns the merged params of the bundle
+func (b *Bundle) LoadParams() (map[string]string, error) {
+	params := map[string]string{}
+	for _, p := range b.Params {
+		f, err := b.path(p)
+		if err != nil {
+			return nil, err
+		}
+		loaded, err := parseParamsFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range loaded {
+			params[k] = v
+		}
+	}
+	return params, nil
+}
+
+// Returns the location of the manifest path {rel} within the bundle.  Absolute paths and paths leading
+// outside of the bundle (eg. ../secrets.env) are rejected
+func (b *Bundle) path(rel string) (string, error) {
+	f := filepath.Join(b.dir, filepath.FromSlash(rel))
+	if filepath.IsAbs(filepath.FromSlash(rel)) || !b.contains(f) {
+		return "", fmt.Errorf("bundle path '%s' is outside of the bundle", rel)
+	}
+	return f, nil
+}
+
+// Returns true if {path} is within the bundle once symlinks are resolved.  Paths which do not exist
+// are checked as is
+func (b *Bundle) contains(path string) bool {
+	if !withinDir(b.dir, path) {
+		return false
+	}
+	dir, err := filepath.EvalSymlinks(b.dir)
+	if err != nil {
+		return true
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return true
+	}
+	return withinDir(dir, resolved)
+}
+
+// Returns true if {path} is {dir} or located beneath it
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// Merges the values of {other} into the context replacing existing values
+func (ctx *TemplateContext) merge(other *TemplateContext) {
+	for env, te := range other.Environments {
+		if te == nil {
+			continue
+		}
+		for app, values := range te.Apps {
+			ctx.SetAppValues(env, app, values)
+		}
+	}
+}
+
+// Extracts the tar.gz {archive} into {dir}.  Entries outside of {dir} are rejected
+func extractArchive(archive, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(h.Name))
+		if !withinDir(dir, target) {
+			return fmt.Errorf("archive entry '%s' is outside of the bundle", h.Name)
+		}
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Returns the directory holding the manifest of an extracted archive.  Archives are commonly created
+// from the parent of the bundle directory (eg. tar czf release.tar.gz release/) so a single top level
+// directory is descended into
+func archiveRoot(dir string) string {
+	if _, err := bundleManifest(dir); err == nil {
+		return dir
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err == nil && len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name())
+	}
+	return dir
+}
@@ -0,0 +1,152 @@
+package marathon
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/stretchr/testify/assert"
+)
+
+var bundleFiles = map[string]string{
+	"bundle.yaml":      "name: product\nversion: 1.4.0\ndescriptors: [ \"apps/*.yaml\" ]\ncontexts: [ \"base.json\", \"prod.json\" ]\nparams: [ \"params.env\" ]\n",
+	"apps/api.yaml":    "id: /product/api\n---\nid: /product/backend\napps:\n  - id: worker\n",
+	"apps/notes.txt":   "not a descriptor",
+	"base.json":        `{"environments": {"prod": {"apps": {"api": {"mem": 128, "cpus": 0.1}}}}}`,
+	"prod.json":        `{"environments": {"prod": {"apps": {"api": {"mem": 256}}}}}`,
+	"params.env":       "TAG=1.4.0\n",
+	"groups/skip.yaml": "id: /product/skipped\n",
+}
+
+func writeBundle(t *testing.T, dir string) {
+	for name, content := range bundleFiles {
+		f := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(f), 0700))
+		assert.NoError(t, ioutil.WriteFile(f, []byte(content), 0600))
+	}
+}
+
+func writeBundleArchive(t *testing.T, archive string, entries map[string]string) {
+	f, err := os.Create(archive)
+	assert.NoError(t, err)
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	for name, content := range entries {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+}
+
+func assertBundle(t *testing.T, b *Bundle) {
+	assert.Equal(t, "product@1.4.0", b.Release())
+
+	files, err := b.Files()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(b.dir, "apps", "api.yaml")}, files)
+
+	descriptors, err := parseDescriptors(marathon.NewMarathonClient("http://localhost:8080", "", "", ""), files[0], bundleFiles["apps/api.yaml"], nil)
+	assert.NoError(t, err)
+	assert.Len(t, descriptors, 2)
+	assert.True(t, descriptors[0].IsApplication())
+	assert.False(t, descriptors[1].IsApplication())
+
+	ctx, err := b.Context()
+	assert.NoError(t, err)
+	api := ctx.Environments["prod"].Apps["api"]
+	assert.Equal(t, float64(256), api["mem"])
+	assert.Equal(t, 0.1, api["cpus"])
+
+	params, err := b.LoadParams()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"TAG": "1.4.0"}, params)
+}
+
+func TestOpenBundleDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depcon-bundle-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeBundle(t, dir)
+
+	assert.True(t, isBundle(dir))
+	assert.False(t, isBundle(filepath.Join(dir, "apps")))
+
+	b, err := openBundle(dir)
+	assert.NoError(t, err)
+	defer b.Close()
+	assertBundle(t, b)
+}
+
+func TestOpenBundleArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depcon-bundle-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	entries := map[string]string{}
+	for name, content := range bundleFiles {
+		entries["release/"+name] = content
+	}
+	archive := filepath.Join(dir, "release.tar.gz")
+	writeBundleArchive(t, archive, entries)
+	assert.True(t, isBundle(archive))
+
+	b, err := openBundle(archive)
+	assert.NoError(t, err)
+	assertBundle(t, b)
+
+	b.Close()
+	_, err = os.Stat(b.dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestOpenBundleArchiveRejectsOutsideEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depcon-bundle-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "release.tgz")
+	writeBundleArchive(t, archive, map[string]string{"../escape.yaml": "id: /escape\n"})
+	_, err = openBundle(archive)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "outside of the bundle")
+}
+
+func TestBundleRejectsOutsidePaths(t *testing.T) {
+	root, err := ioutil.TempDir("", "depcon-bundle-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "release")
+	writeBundle(t, dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "secrets.env"), []byte("TOKEN=x\n"), 0600))
+	assert.NoError(t, os.Symlink(root, filepath.Join(dir, "linked")))
+
+	b := &Bundle{dir: dir, Params: []string{"../secrets.env"}}
+	_, err = b.LoadParams()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "outside of the bundle")
+
+	b = &Bundle{dir: dir, Contexts: []string{"/etc/passwd"}}
+	_, err = b.Context()
+	assert.Error(t, err)
+
+	b = &Bundle{dir: dir, Descriptors: []string{"../*.env"}}
+	_, err = b.Files()
+	assert.Error(t, err)
+
+	b = &Bundle{dir: dir, Params: []string{"linked/secrets.env"}}
+	_, err = b.LoadParams()
+	assert.Error(t, err)
+
+	b = &Bundle{dir: dir, Descriptors: []string{"linked/*.env"}}
+	_, err = b.Files()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "resolves outside of the bundle")
+}
@@ -0,0 +1,179 @@
+package marathon
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/pkg/httpclient"
+	"github.com/ContainX/depcon/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	ALL_FIELDS_FLAG = "all"
+	MissingValue    = "<missing>"
+)
+
+var (
+	ErrorCompareEnvs = errors.New("At least two environments must be specified with --envs (eg. --envs qa,prod)")
+
+	// The definition fields compared between environments
+	compareFields = []string{
+		"/container/docker/image", "/cpus", "/mem", "/disk", "/gpus", "/instances",
+		"/env", "/labels", "/constraints", "/healthChecks",
+	}
+)
+
+// A side-by-side comparison of an app or group across environments
+type Comparison struct {
+	ID   string           `json:"id"`
+	Envs []string         `json:"envs"`
+	Rows []*ComparisonRow `json:"rows"`
+}
+
+type ComparisonRow struct {
+	Path   string   `json:"path"`
+	Values []string `json:"values"`
+	Drift  bool     `json:"drift"`
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare [appOrGroupId]",
+	Short: "Compares [appOrGroupId] across environments to find configuration drift",
+	Long: `Fetches the same app or group from each of the --envs and shows the image, resources, instances,
+env vars, labels, constraints and health checks side-by-side.  Runtime fields are ignored and only the
+fields which differ are shown unless --all is specified.  Sensitive env values are compared as is but masked
+in the output, a masked value which differs from the first environment is shown as '` + marathon.MaskedValue + ` (differs)'.
+
+    eg. depcon mar compare /product/api --envs qa,prod`,
+	Run: compareEnvs,
+}
+
+func init() {
+	compareCmd.Flags().StringSlice(ENVS_FLAG, nil, "The environments to compare (eg. --envs qa,prod)")
+	compareCmd.Flags().Bool(ALL_FIELDS_FLAG, false, "Show all compared fields instead of only those which differ")
+}
+
+func compareEnvs(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+	envs, _ := cmd.Flags().GetStringSlice(ENVS_FLAG)
+	if len(envs) < 2 {
+		exitWithError(ErrorCompareEnvs)
+	}
+
+	id := args[0]
+	perEnv := make([]map[string]interface{}, len(envs))
+	for i, env := range envs {
+		c, err := clientForEnv(cmd, env)
+		if err != nil {
+			exitWithError(err)
+		}
+		fields, err := definitionFields(c, id)
+		if err != nil {
+			exitWithError(err)
+		}
+		perEnv[i] = fields
+	}
+
+	all, _ := cmd.Flags().GetBool(ALL_FIELDS_FLAG)
+	cli.Output(templateFor(T_COMPARISON, buildComparison(id, envs, perEnv, all)), nil)
+}
+
+// Returns the flattened compared fields of the app {id} or, if no such app exists, of every app within
+// the group {id} keyed by app id.  Missing apps/groups return no fields
+func definitionFields(c marathon.Marathon, id string) (map[string]interface{}, error) {
+	app, err := c.GetApplication(id)
+	if err == nil {
+		return marathon.Flatten(app.Descriptor(), compareFields...)
+	}
+	if err != httpclient.ErrorNotFound {
+		return nil, err
+	}
+
+	group, err := c.GetGroup(id)
+	if err != nil {
+		if err == httpclient.ErrorNotFound {
+			return map[string]interface{}{}, nil
+		}
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	for _, app := range group.FlattenApps() {
+		flat, err := marathon.Flatten(app.Descriptor(), compareFields...)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range flat {
+			fields[utils.TrimRootPath(app.ID)+":"+k] = v
+		}
+	}
+	return fields, nil
+}
+
+// Returns true if the flattened {path} is the literal value of a sensitive env var (eg. /env/DB_PASSWORD
+// or product/api:/env/DB_PASSWORD).  Secret references (/env/DB_PASSWORD/secret) hold no value
+func isSensitivePath(path string) bool {
+	i := strings.Index(path, "/env/")
+	if i < 0 {
+		return false
+	}
+	name := path[i+len("/env/"):]
+	return !strings.Contains(name, "/") && marathon.IsSensitiveEnv(name)
+}
+
+// Masks the values of the row once drift has been determined.  Values which differ from the first
+// environment are marked so drift in sensitive values is still visible
+func (r *ComparisonRow) mask() {
+	first := r.Values[0]
+	for i, v := range r.Values {
+		switch {
+		case v == MissingValue:
+		case i > 0 && v != first:
+			r.Values[i] = marathon.MaskedValue + " (differs)"
+		default:
+			r.Values[i] = marathon.MaskedValue
+		}
+	}
+}
+
+func buildComparison(id string, envs []string, perEnv []map[string]interface{}, all bool) *Comparison {
+	paths := map[string]bool{}
+	for _, fields := range perEnv {
+		for p := range fields {
+			paths[p] = true
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	cmp := &Comparison{ID: id, Envs: envs, Rows: []*ComparisonRow{}}
+	for _, p := range sorted {
+		row := &ComparisonRow{Path: p, Values: make([]string, len(envs))}
+		for i, fields := range perEnv {
+			if v, ok := fields[p]; ok {
+				row.Values[i] = marathon.FormatValue(v)
+			} else {
+				row.Values[i] = MissingValue
+			}
+			if i > 0 && row.Values[i] != row.Values[0] {
+				row.Drift = true
+			}
+		}
+		if isSensitivePath(p) {
+			row.mask()
+		}
+		if row.Drift || all {
+			cmp.Rows = append(cmp.Rows, row)
+		}
+	}
+	return cmp
+}
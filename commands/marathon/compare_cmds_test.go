@@ -0,0 +1,34 @@
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildComparison(t *testing.T) {
+	qa := map[string]interface{}{"/cpus": 0.5, "/instances": float64(1), "/env/DEBUG": "true"}
+	prod := map[string]interface{}{"/cpus": 0.5, "/instances": float64(4)}
+
+	cmp := buildComparison("/product/api", []string{"qa", "prod"}, []map[string]interface{}{qa, prod}, false)
+
+	assert.Equal(t, 2, len(cmp.Rows))
+	assert.Equal(t, &ComparisonRow{Path: "/env/DEBUG", Values: []string{"true", MissingValue}, Drift: true}, cmp.Rows[0])
+	assert.Equal(t, &ComparisonRow{Path: "/instances", Values: []string{"1", "4"}, Drift: true}, cmp.Rows[1])
+
+	qa["/env/DB_PASSWORD"], prod["/env/DB_PASSWORD"] = "qa-secret", "prod-secret"
+	qa["/env/API_TOKEN"], prod["/env/API_TOKEN"] = "same", "same"
+	qa["/env/DB_PASSWORD/secret"], prod["/env/DB_PASSWORD/secret"] = "db", "db-prod"
+	cmp = buildComparison("/product/api", []string{"qa", "prod"}, []map[string]interface{}{qa, prod}, false)
+	assert.Equal(t, 4, len(cmp.Rows))
+	assert.Equal(t, &ComparisonRow{Path: "/env/DB_PASSWORD", Values: []string{"********", "******** (differs)"}, Drift: true}, cmp.Rows[0])
+	assert.Equal(t, &ComparisonRow{Path: "/env/DB_PASSWORD/secret", Values: []string{"db", "db-prod"}, Drift: true}, cmp.Rows[1])
+	delete(qa, "/env/DB_PASSWORD")
+	delete(qa, "/env/DB_PASSWORD/secret")
+	delete(prod, "/env/DB_PASSWORD")
+	delete(prod, "/env/DB_PASSWORD/secret")
+
+	all := buildComparison("/product/api", []string{"qa", "prod"}, []map[string]interface{}{qa, prod}, true)
+	assert.Equal(t, 4, len(all.Rows))
+	assert.Equal(t, &ComparisonRow{Path: "/env/API_TOKEN", Values: []string{"********", "********"}}, all.Rows[1])
+}
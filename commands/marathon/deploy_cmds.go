@@ -7,12 +7,19 @@ import (
 	"github.com/ContainX/depcon/marathon"
 	"github.com/ContainX/depcon/pkg/cli"
 	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/ContainX/depcon/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
+const (
+	PARALLEL_FLAG = "parallel"
+)
+
 var deployCmd = &cobra.Command{
 	Use:   "deploy",
 	Short: "Marathon deployment management",
@@ -64,13 +71,38 @@ var deleteIfDeployingCmd = &cobra.Command{
 }
 
 var deployCreateCmd = &cobra.Command{
-	Use:   "create",
+	Use:   "create [file, glob, url or - ...]",
 	Short: "Creates a new app or group by introspecting the incoming descriptor.  Useful for deployment pipelines",
 	Long: `
 Creates a new app or group by introspecting the incoming descriptor.  Useful for deployment pipelines.
 
 This command has a small penalty of unmarshalling the descriptor twice. One for introspection and
 the other for delegation to the origin (app or group)
+
+Multiple files and/or globs may be specified.  A dependency graph is built from the declared app and
+group "dependencies" and independent descriptors are deployed concurrently (see --parallel).
+Dependents are only deployed once their dependencies are healthy and a summary is printed at the end.
+YAML files may hold multiple apps and groups separated by '---'.
+
+A bundle (a directory or .tar.gz archive with a bundle.yaml manifest) deploys the descriptors listed
+within the manifest using the template contexts and params files shipped with it.  Paths are relative
+to the bundle and --tempctx / --param take precedence over the bundle's contexts and params.
+
+    name: product
+    version: 1.4.0
+    descriptors: [ "apps/*.yaml", "groups/backend.yaml" ]
+    contexts: [ "template-context.json" ]
+    params: [ "params/common.env" ]
+
+Descriptors (and --tempctx) may also be http(s) urls fetched with --source-token or --source-user.  A
+url may pin its content with a sha256 checksum fragment which is verified before deploying.  '-' reads a
+descriptor from stdin.  The format of stdin and urls without a .json / .yaml extension is taken from
+--input-format or detected from the content.
+
+    eg. depcon mar deploy create 'services/*.yml' --parallel 3 -f
+        depcon mar deploy create product-1.4.0.tar.gz -f
+        depcon mar deploy create 'https://artifacts/product/api.yaml#sha256=<hex>' --source-token $TOKEN
+        render-descriptors | depcon mar deploy create - --input-format yaml
 	`,
 
 	Run: deployAppOrGroup,
@@ -84,9 +116,22 @@ func init() {
 
 func addDeployCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolP(WAIT_FLAG, "w", false, "Wait for deployment to become healthy")
-	cmd.Flags().String(TEMPLATE_CTX_FLAG, DEFAULT_CTX, "Provides data per environment in JSON form to do a first pass parse of descriptor as template")
 	cmd.Flags().BoolP(FORCE_FLAG, "f", false, "Force deployment (updates application if it already exists)")
 	cmd.Flags().Bool(STOP_DEPLOYS_FLAG, false, "Stop an existing deployment for this app (if exists) and use this revision")
+	addRenderFlags(cmd)
+
+	cmd.Flags().Bool(DRYRUN_FLAG, false, "Preview the parsed template - don't actually deploy")
+
+	cmd.Flags().DurationP(TIMEOUT_FLAG, "t", time.Duration(0), "Max duration to wait for application health (ex. 90s | 2m). See docs for ordering")
+	cmd.Flags().Int(PARALLEL_FLAG, 4, "Max descriptors deployed concurrently when multiple files are specified")
+	addDeployEnvsFlags(cmd)
+	addLockFlags(cmd)
+
+}
+
+// Adds the flags used to render and parse descriptors the same way 'deploy create' does
+func addRenderFlags(cmd *cobra.Command) {
+	cmd.Flags().String(TEMPLATE_CTX_FLAG, DEFAULT_CTX, "Provides data per environment in JSON form to do a first pass parse of descriptor as template")
 	cmd.Flags().BoolP(IGNORE_MISSING, "i", false, `Ignore missing ${PARAMS} that are declared in app config that could not be resolved
                         CAUTION: This can be dangerous if some params define versions or other required information.`)
 	cmd.Flags().StringP(ENV_FILE_FLAG, "c", "", `Adds a file with a param(s) that can be used for substitution.
@@ -94,11 +139,7 @@ func addDeployCreateFlags(cmd *cobra.Command) {
 	cmd.Flags().StringSliceP(PARAMS_FLAG, "p", nil, `Adds a param(s) that can be used for substitution.
                   eg. -p MYVAR=value would replace ${MYVAR} with "value" in the application file.
                   These take precidence over env vars`)
-
-	cmd.Flags().Bool(DRYRUN_FLAG, false, "Preview the parsed template - don't actually deploy")
-
-	cmd.Flags().DurationP(TIMEOUT_FLAG, "t", time.Duration(0), "Max duration to wait for application health (ex. 90s | 2m). See docs for ordering")
-
+	cmd.Flags().String(INPUT_FORMAT_FLAG, "", "Format (json | yaml) of descriptors read from stdin or urls.  Detected from the url extension or content if not set")
 }
 
 func deployAppOrGroup(cmd *cobra.Command, args []string) {
@@ -107,50 +148,72 @@ func deployAppOrGroup(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	filename := args[0]
+	files, err := expandDescriptorArgs(args)
+	if err != nil {
+		exitWithError(err)
+	}
+	defer removeSources()
+	if files, err = resolveSources(cmd, files); err != nil {
+		exitWithError(err)
+	}
+	if len(files) == 1 && isBundle(files[0]) {
+		deployBundle(cmd, files[0])
+		return
+	}
+	if envs := targetEnvs(cmd); len(envs) > 0 {
+		deployToEnvs(cmd, deploySettingsFromFlags(cmd), files, envs)
+		return
+	}
+	if len(files) > 1 || isMultiDocument(files[0]) {
+		deployMany(cmd, deploySettingsFromFlags(cmd), files)
+		return
+	}
+
+	filename := files[0]
 	wait, _ := cmd.Flags().GetBool(WAIT_FLAG)
 	force, _ := cmd.Flags().GetBool(FORCE_FLAG)
-	paramsFile, _ := cmd.Flags().GetString(ENV_FILE_FLAG)
-	params, _ := cmd.Flags().GetStringSlice(PARAMS_FLAG)
 	ignore, _ := cmd.Flags().GetBool(IGNORE_MISSING)
 	stop_deploy, _ := cmd.Flags().GetBool(STOP_DEPLOYS_FLAG)
 	tempctx, _ := cmd.Flags().GetString(TEMPLATE_CTX_FLAG)
 	dryrun, _ := cmd.Flags().GetBool(DRYRUN_FLAG)
 	options := &marathon.CreateOptions{Wait: wait, Force: force, ErrorOnMissingParams: !ignore, StopDeploy: stop_deploy, DryRun: dryrun}
 
+	if options.Strict = viper.GetBool(STRICT_PARSE_FLAG); options.Strict {
+		options.Source = descriptorSource(filename)
+	}
+
 	descriptor := ParseDescriptor(tempctx, filename, "")
-	et, err := encoding.NewEncoderFromFileExt(filename)
+	et, err := encoding.EncoderTypeFromExt(filename)
 	if err != nil {
 		exitWithError(err)
 	}
 
-	ag := &marathon.AppOrGroup{}
-	if err := et.UnMarshalStr(descriptor, ag); err != nil {
+	ag, err := decodeAppOrGroup(et, descriptor, options)
+	if err != nil {
 		exitWithError(err)
 	}
 
-	if paramsFile != "" {
-		envParams, _ := parseParamsFile(paramsFile)
-		options.EnvParams = envParams
-	} else {
-		options.EnvParams = make(map[string]string)
+	options.EnvParams = envParamsFromFlags(cmd)
+	options.Metadata = deployMetadata(viper.GetString(ENV_NAME), filename)
+	if options.Verifier, err = policyVerifier(viper.GetString(ENV_NAME)); err != nil {
+		exitWithError(err)
 	}
 
-	if params != nil {
-		for _, p := range params {
-			if strings.Contains(p, "=") {
-				v := strings.Split(p, "=")
-				options.EnvParams[v[0]] = v[1]
-			}
+	release := func() {}
+	if !dryrun {
+		if release, err = acquireDeployLocks(cmd, client(cmd), []string{ag.ID}); err != nil {
+			exitWithError(err)
 		}
 	}
 
 	if ag.IsApplication() {
 		result, e := client(cmd).CreateApplicationFromString(filename, descriptor, options)
+		release()
 		outputDeployment(result, e)
 		cli.Output(templateFor(T_APPLICATION, result), e)
 	} else {
 		result, e := client(cmd).CreateGroupFromString(filename, descriptor, options)
+		release()
 		outputDeployment(result, e)
 
 		if e != nil {
@@ -162,6 +225,285 @@ func deployAppOrGroup(cmd *cobra.Command, args []string) {
 	}
 }
 
+// Builds the substitution params from the --env-file and --param flags.  Params take precedence
+// over those declared within the env file
+func envParamsFromFlags(cmd *cobra.Command) map[string]string {
+	envParams := make(map[string]string)
+
+	if paramsFile, _ := cmd.Flags().GetString(ENV_FILE_FLAG); paramsFile != "" {
+		if fileParams, err := parseParamsFile(paramsFile); err == nil {
+			envParams = fileParams
+		}
+	}
+
+	if params, _ := cmd.Flags().GetStringSlice(PARAMS_FLAG); params != nil {
+		for _, p := range params {
+			if strings.Contains(p, "=") {
+				v := strings.Split(p, "=")
+				envParams[v[0]] = v[1]
+			}
+		}
+	}
+	return envParams
+}
+
+// Expands any globs within {args} into the matching descriptor files.  Stdin ('-') and urls are kept as is
+func expandDescriptorArgs(args []string) ([]string, error) {
+	files := []string{}
+	for _, arg := range args {
+		if arg == STDIN_SOURCE || isRemoteSource(arg) {
+			files = append(files, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			if !strings.ContainsAny(arg, "*?[") {
+				// let the existing file handling report the missing file
+				files = append(files, arg)
+				continue
+			}
+			return nil, fmt.Errorf("No descriptors match '%s'", arg)
+		}
+		for _, m := range matches {
+			if !utils.StringInSlice(m, files) {
+				files = append(files, m)
+			}
+		}
+	}
+	return files, nil
+}
+
+// Deployment settings shared by multi descriptor and multi environment deployments
+type deploySettings struct {
+	force      bool
+	wait       bool
+	stopDeploy bool
+	dryrun     bool
+	ignore     bool
+	parallel   int
+	timeout    time.Duration
+	ctx        *TemplateContext
+	params     map[string]string
+	// if true the policies of the environment are evaluated when descriptors are loaded
+	enforcePolicies bool
+	// if true descriptors declaring unknown fields are rejected
+	strict bool
+	// directory searched for *.tmpl files included by descriptor templates
+	rootDir string
+	// stamped as the release metadata label when deploying a bundle
+	release string
+}
+
+func deploySettingsFromFlags(cmd *cobra.Command) *deploySettings {
+	s := &deploySettings{params: envParamsFromFlags(cmd), enforcePolicies: true}
+	s.force, _ = cmd.Flags().GetBool(FORCE_FLAG)
+	s.wait, _ = cmd.Flags().GetBool(WAIT_FLAG)
+	s.ignore, _ = cmd.Flags().GetBool(IGNORE_MISSING)
+	s.stopDeploy, _ = cmd.Flags().GetBool(STOP_DEPLOYS_FLAG)
+	s.dryrun, _ = cmd.Flags().GetBool(DRYRUN_FLAG)
+	s.strict = viper.GetBool(STRICT_PARSE_FLAG)
+	s.parallel, _ = cmd.Flags().GetInt(PARALLEL_FLAG)
+	s.timeout, _ = cmd.Flags().GetDuration(TIMEOUT_FLAG)
+	if s.timeout <= 0 {
+		s.timeout = marathon.DefaultTimeout
+	}
+
+	tempctx, _ := cmd.Flags().GetString(TEMPLATE_CTX_FLAG)
+	ctx, err := LoadTemplateContext(tempctx)
+	if err != nil {
+		exitWithError(err)
+	}
+	s.ctx = ctx
+	return s
+}
+
+// Renders and parses {files} for the environment {env}.  {params} are merged beneath the params
+// specified on the command line
+func (s *deploySettings) load(c marathon.Marathon, files []string, env string, params map[string]string) ([]*Descriptor, error) {
+	merged := map[string]string{}
+	for k, v := range params {
+		merged[k] = v
+	}
+	for k, v := range s.params {
+		merged[k] = v
+	}
+
+	var verifier marathon.Verifier
+	if s.enforcePolicies {
+		v, err := policyVerifier(env)
+		if err != nil {
+			return nil, err
+		}
+		verifier = v
+	}
+
+	descriptors := []*Descriptor{}
+	for _, f := range files {
+		rendered, err := renderDescriptor(s.ctx, f, s.rootDir, env)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", sourceName(f), err.Error())
+		}
+		options := &marathon.CreateOptions{ErrorOnMissingParams: !s.ignore, EnvParams: merged, Metadata: deployMetadata(env, f), Verifier: verifier}
+		if options.Metadata != nil {
+			options.Metadata.Release = s.release
+		}
+		if s.strict {
+			options.Strict, options.Source = true, descriptorSource(f)
+		}
+		parsed, err := parseDescriptors(c, f, rendered, options)
+		if _, located := err.(encoding.DecodeErrors); located {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %s", f, err.Error())
+		}
+		descriptors = append(descriptors, parsed...)
+	}
+	return descriptors, nil
+}
+
+// Acquires deployment locks on {descriptors} unless this is a dry run
+func (s *deploySettings) lock(cmd *cobra.Command, c marathon.Marathon, descriptors []*Descriptor) (func(), error) {
+	if s.dryrun {
+		return func() {}, nil
+	}
+	ids := []string{}
+	for _, d := range descriptors {
+		ids = append(ids, d.ID())
+	}
+	return acquireDeployLocks(cmd, c, ids)
+}
+
+// Deploys {descriptors} in dependency order.  If {gate} is true every descriptor must become healthy
+// to be considered successful, otherwise only those with dependents are waited on
+func (s *deploySettings) deploy(c marathon.Marathon, descriptors []*Descriptor, gate bool) ([]*DeployResult, error) {
+	nodes, err := buildDependencyGraph(descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.dryrun {
+		results := []*DeployResult{}
+		for _, key := range dependencyOrder(nodes) {
+			results = append(results, newDeployResult(nodes[key], DeployPlanned))
+		}
+		return results, nil
+	}
+
+	return executeGraph(nodes, s.parallel, func(n *depNode) error {
+		healthy := gate || n.dependents > 0
+		return deployDescriptor(c, n.desc, s.wait || healthy, s.force, s.stopDeploy, healthy, s.timeout)
+	}), nil
+}
+
+// Deploys the bundle at {path} to the current environment or to the environments targeted by --envs
+func deployBundle(cmd *cobra.Command, path string) {
+	b, err := openBundle(path)
+	if err != nil {
+		exitWithError(err)
+	}
+	onExit(b.Close)
+	defer b.Close()
+
+	s := deploySettingsFromFlags(cmd)
+	if err := s.useBundle(cmd, b); err != nil {
+		exitWithError(err)
+	}
+	files, err := b.Files()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if envs := targetEnvs(cmd); len(envs) > 0 {
+		deployToEnvs(cmd, s, files, envs)
+	} else {
+		deployMany(cmd, s, files)
+	}
+}
+
+// Renders descriptors with the contexts and params of bundle {b}.  The --tempctx and --param flags
+// take precedence over those of the bundle
+func (s *deploySettings) useBundle(cmd *cobra.Command, b *Bundle) error {
+	ctx, err := b.Context()
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed(TEMPLATE_CTX_FLAG) {
+		ctx.merge(s.ctx)
+	}
+	params, err := b.LoadParams()
+	if err != nil {
+		return err
+	}
+	for k, v := range s.params {
+		params[k] = v
+	}
+	s.ctx, s.params, s.rootDir, s.release = ctx, params, b.dir, b.Release()
+	return nil
+}
+
+// Deploys multiple descriptors in dependency order.  Independent descriptors are deployed concurrently
+// and any descriptor with dependents is waited on until healthy before its dependents start
+func deployMany(cmd *cobra.Command, s *deploySettings, files []string) {
+	descriptors, err := s.load(client(cmd), files, viper.GetString(ENV_NAME), nil)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	release, err := s.lock(cmd, client(cmd), descriptors)
+	if err != nil {
+		exitWithError(err)
+	}
+	results, err := s.deploy(client(cmd), descriptors, false)
+	release()
+	if err != nil {
+		exitWithError(err)
+	}
+	cli.Output(templateFor(T_DEPLOY_SUMMARY, results), nil)
+
+	for _, r := range results {
+		if r.Status != DeploySuccess && r.Status != DeployPlanned {
+			runExitHooks()
+			os.Exit(1)
+		}
+	}
+}
+
+// Deploys a single parsed descriptor.  If {healthy} is true all applications must pass their health
+// checks before this returns successfully
+func deployDescriptor(c marathon.Marathon, d *Descriptor, wait, force, stopDeploy, healthy bool, timeout time.Duration) error {
+	id := d.ID()
+	if stopDeploy {
+		if deployment, err := c.CancelAppDeployment(id, !d.IsApplication()); err == nil && deployment != nil {
+			c.WaitForDeployment(deployment.DeploymentID, time.Second*30)
+		}
+	}
+
+	apps := []*marathon.Application{}
+	if d.IsApplication() {
+		apps = append(apps, &marathon.Application{ID: id})
+		if _, err := c.CreateApplication(d.App, wait, force); err != nil {
+			return err
+		}
+	} else {
+		apps = d.Group.FlattenApps()
+		if _, err := c.CreateGroup(d.Group, wait, force); err != nil {
+			return err
+		}
+	}
+
+	if healthy {
+		for _, app := range apps {
+			if err := c.WaitForApplicationWithOptions(app.ID, timeout, &marathon.WaitOptions{Healthy: true}); err != nil {
+				return fmt.Errorf("'%s' did not become healthy: %s", app.ID, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
 func outputDeployment(result interface{}, e error) {
 	if e != nil && e == marathon.ErrorAppExists {
 		exitWithError(errors.New(fmt.Sprintf("%s, consider using the --force flag to update when an application exists", e.Error())))
@@ -0,0 +1,135 @@
+package marathon
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+const (
+	ENVS_FLAG          = "envs"
+	ALL_ENVS_FLAG      = "all-envs"
+	PARALLEL_ENVS_FLAG = "parallel-envs"
+
+	EnvHalted = "halted"
+)
+
+var (
+	ErrorEnvHalted = errors.New("not deployed - a previous environment failed")
+)
+
+// The outcome of deploying to a single environment
+type EnvDeployResult struct {
+	Env     string          `json:"env"`
+	Status  string          `json:"status"`
+	Error   string          `json:"error,omitempty"`
+	Results []*DeployResult `json:"results,omitempty"`
+}
+
+func addDeployEnvsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(ENVS_FLAG, nil, "Deploy to each of the specified environments (eg. --envs dc1,dc2,dc3)")
+	cmd.Flags().Bool(ALL_ENVS_FLAG, false, "Deploy to every configured environment")
+	cmd.Flags().Bool(PARALLEL_ENVS_FLAG, false, `Deploy to all environments concurrently.  By default environments are deployed
+                        one at a time and the rollout halts at the first environment which fails or does not become healthy`)
+}
+
+// Returns the environments targeted by the --envs or --all-envs flags or nil if neither was specified
+func targetEnvs(cmd *cobra.Command) []string {
+	if all, _ := cmd.Flags().GetBool(ALL_ENVS_FLAG); all {
+		envs := configFile.GetEnvironments()
+		sort.Strings(envs)
+		return envs
+	}
+	envs, _ := cmd.Flags().GetStringSlice(ENVS_FLAG)
+	return envs
+}
+
+// Deploys {files} to each of the {envs}.  Every environment renders the descriptors with its own template
+// context and params and uses its own client
+func deployToEnvs(cmd *cobra.Command, s *deploySettings, files []string, envs []string) {
+	parallel, _ := cmd.Flags().GetBool(PARALLEL_ENVS_FLAG)
+
+	results := make([]*EnvDeployResult, len(envs))
+	if parallel {
+		var wg sync.WaitGroup
+		for i, env := range envs {
+			wg.Add(1)
+			go func(i int, env string) {
+				defer wg.Done()
+				results[i] = deployToEnv(cmd, s, files, env, false)
+			}(i, env)
+		}
+		wg.Wait()
+	} else {
+		halted := false
+		for i, env := range envs {
+			if halted {
+				results[i] = &EnvDeployResult{Env: env, Status: EnvHalted, Error: ErrorEnvHalted.Error()}
+				continue
+			}
+			results[i] = deployToEnv(cmd, s, files, env, true)
+			halted = results[i].Status == DeployFailed
+		}
+	}
+
+	cli.Output(templateFor(T_ENV_DEPLOY_SUMMARY, results), nil)
+	for _, r := range results {
+		if r.Status != DeploySuccess && r.Status != DeployPlanned {
+			runExitHooks()
+			os.Exit(1)
+		}
+	}
+}
+
+// Returns the params configured for the environment {env} or nil if there is no configuration
+func envParams(env string) map[string]string {
+	if configFile == nil {
+		return nil
+	}
+	if ce, err := configFile.GetEnvironment(env); err == nil {
+		return ce.Params
+	}
+	return nil
+}
+
+func deployToEnv(cmd *cobra.Command, s *deploySettings, files []string, env string, gate bool) *EnvDeployResult {
+	r := &EnvDeployResult{Env: env, Status: DeploySuccess}
+
+	c, err := clientForEnv(cmd, env)
+	if err != nil {
+		return r.failed(err)
+	}
+
+	descriptors, err := s.load(c, files, env, envParams(env))
+	if err != nil {
+		return r.failed(err)
+	}
+
+	release, err := s.lock(cmd, c, descriptors)
+	if err != nil {
+		return r.failed(err)
+	}
+	r.Results, err = s.deploy(c, descriptors, gate)
+	release()
+	if err != nil {
+		return r.failed(err)
+	}
+
+	for _, dr := range r.Results {
+		if dr.Status == DeployPlanned {
+			r.Status = DeployPlanned
+		} else if dr.Status != DeploySuccess {
+			r.Status = DeployFailed
+		}
+	}
+	return r
+}
+
+func (r *EnvDeployResult) failed(err error) *EnvDeployResult {
+	r.Status, r.Error = DeployFailed, err.Error()
+	return r
+}
@@ -0,0 +1,272 @@
+package marathon
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ContainX/depcon/utils"
+)
+
+const (
+	DeploySuccess = "success"
+	DeployFailed  = "failed"
+	DeploySkipped = "skipped"
+	DeployPlanned = "planned"
+)
+
+// The outcome of deploying a single descriptor as part of a multi-descriptor deployment
+type DeployResult struct {
+	ID        string   `json:"id"`
+	Kind      string   `json:"kind"`
+	File      string   `json:"file"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+	Status    string   `json:"status"`
+	Elapsed   string   `json:"elapsed,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// A descriptor and the descriptors it depends on within the same deployment
+type depNode struct {
+	key        string
+	desc       *Descriptor
+	deps       []string
+	dependents int
+}
+
+// Builds the dependency graph between {descriptors} using the declared app and group dependencies.
+// Dependencies which are not part of {descriptors} are assumed to already exist and are ignored
+func buildDependencyGraph(descriptors []*Descriptor) (map[string]*depNode, error) {
+	nodes := map[string]*depNode{}
+	owners := map[string]string{}
+
+	for _, d := range descriptors {
+		key := utils.TrimRootPath(d.ID())
+		if _, exists := nodes[key]; exists {
+			return nil, fmt.Errorf("'%s' is declared more than once (%s)", d.ID(), d.Filename)
+		}
+		nodes[key] = &depNode{key: key, desc: d}
+		owners[key] = key
+		if !d.IsApplication() {
+			for _, app := range d.Group.FlattenApps() {
+				owners[utils.TrimRootPath(app.ID)] = key
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		for _, dep := range descriptorDependencies(n.desc) {
+			owner, found := owners[dep]
+			if !found || owner == n.key || utils.StringInSlice(owner, n.deps) {
+				continue
+			}
+			n.deps = append(n.deps, owner)
+			nodes[owner].dependents++
+		}
+		sort.Strings(n.deps)
+	}
+
+	if cycle := findCycle(nodes); cycle != nil {
+		return nil, fmt.Errorf("Circular dependency detected: %s", strings.Join(cycle, " -> "))
+	}
+	return nodes, nil
+}
+
+// Returns the resolved (absolute, root trimmed) dependencies declared by the descriptor including
+// the dependencies of any applications within a group
+func descriptorDependencies(d *Descriptor) []string {
+	deps := []string{}
+	if d.IsApplication() {
+		return resolveDependencies(d.App.ID, d.App.Dependencies, deps)
+	}
+	deps = resolveDependencies(d.Group.GroupID+"/", d.Group.Dependencies, deps)
+	for _, app := range d.Group.FlattenApps() {
+		deps = resolveDependencies(app.ID, app.Dependencies, deps)
+	}
+	return deps
+}
+
+func resolveDependencies(id string, dependencies []string, deps []string) []string {
+	parent := path.Dir("/" + utils.TrimRootPath(id))
+	for _, dep := range dependencies {
+		if !strings.HasPrefix(dep, "/") {
+			dep = path.Join(parent, dep)
+		}
+		deps = append(deps, utils.TrimRootPath(path.Clean(dep)))
+	}
+	return deps
+}
+
+func findCycle(nodes map[string]*depNode) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var stack []string
+	var visit func(key string) []string
+
+	visit = func(key string) []string {
+		state[key] = visiting
+		stack = append(stack, key)
+		for _, dep := range nodes[key].deps {
+			if state[dep] == visiting {
+				for i, s := range stack {
+					if s == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			}
+			if state[dep] == 0 {
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[key] = visited
+		return nil
+	}
+
+	for _, key := range sortedNodeKeys(nodes) {
+		if state[key] == 0 {
+			if cycle := visit(key); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the node keys in dependency order (dependencies first, ties ordered by key)
+func dependencyOrder(nodes map[string]*depNode) []string {
+	order := []string{}
+	done := map[string]bool{}
+	for len(order) < len(nodes) {
+		progressed := false
+		for _, key := range sortedNodeKeys(nodes) {
+			if done[key] || !depsSatisfied(nodes[key], done) {
+				continue
+			}
+			order = append(order, key)
+			done[key] = true
+			progressed = true
+		}
+		if !progressed {
+			break
+		}
+	}
+	return order
+}
+
+// Runs {fn} for every node once all of its dependencies have completed successfully, running at most
+// {parallel} at once.  Nodes whose dependencies failed are skipped.  Results are returned in dependency order
+func executeGraph(nodes map[string]*depNode, parallel int, fn func(n *depNode) error) []*DeployResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	type completion struct {
+		key     string
+		err     error
+		elapsed time.Duration
+	}
+
+	results := map[string]*DeployResult{}
+	succeeded := map[string]bool{}
+	pending := map[string]bool{}
+	for key := range nodes {
+		pending[key] = true
+	}
+
+	completed := make(chan completion)
+	running := 0
+
+	for len(pending) > 0 || running > 0 {
+		for _, key := range sortedNodeKeys(nodes) {
+			if !pending[key] {
+				continue
+			}
+			n := nodes[key]
+			if failed := failedDependency(n, results); failed != "" {
+				r := newDeployResult(n, DeploySkipped)
+				r.Error = fmt.Sprintf("dependency '%s' did not deploy successfully", failed)
+				results[key] = r
+				delete(pending, key)
+				continue
+			}
+			if running >= parallel || !depsSatisfied(n, succeeded) {
+				continue
+			}
+			delete(pending, key)
+			running++
+			go func(n *depNode) {
+				start := time.Now()
+				err := fn(n)
+				completed <- completion{key: n.key, err: err, elapsed: time.Since(start)}
+			}(n)
+		}
+
+		if running == 0 {
+			// nothing can make progress - remaining nodes are waiting on skipped dependencies
+			if len(pending) > 0 {
+				continue
+			}
+			break
+		}
+
+		c := <-completed
+		running--
+		r := newDeployResult(nodes[c.key], DeploySuccess)
+		r.Elapsed = utils.ElapsedStr(c.elapsed)
+		if c.err != nil {
+			r.Status, r.Error = DeployFailed, c.err.Error()
+		} else {
+			succeeded[c.key] = true
+		}
+		results[c.key] = r
+	}
+
+	ordered := []*DeployResult{}
+	for _, key := range dependencyOrder(nodes) {
+		ordered = append(ordered, results[key])
+	}
+	return ordered
+}
+
+func newDeployResult(n *depNode, status string) *DeployResult {
+	kind := KindGroup
+	if n.desc.IsApplication() {
+		kind = KindApp
+	}
+	return &DeployResult{ID: n.desc.ID(), Kind: kind, File: n.desc.Filename, DependsOn: n.deps, Status: status}
+}
+
+func depsSatisfied(n *depNode, done map[string]bool) bool {
+	for _, dep := range n.deps {
+		if !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+func failedDependency(n *depNode, results map[string]*DeployResult) string {
+	for _, dep := range n.deps {
+		if r, ok := results[dep]; ok && r.Status != DeploySuccess {
+			return dep
+		}
+	}
+	return ""
+}
+
+func sortedNodeKeys(nodes map[string]*depNode) []string {
+	keys := make([]string, 0, len(nodes))
+	for k := range nodes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,59 @@
+package marathon
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/stretchr/testify/assert"
+)
+
+func appDescriptor(id string, deps ...string) *Descriptor {
+	return &Descriptor{Filename: id + ".json", App: &marathon.Application{ID: id, Dependencies: deps}}
+}
+
+func TestDependencyOrder(t *testing.T) {
+	descriptors := []*Descriptor{
+		appDescriptor("/product/web", "api"),
+		appDescriptor("/product/api", "/product/backend/db", "/external/cache"),
+		{Filename: "backend.json", Group: &marathon.Group{GroupID: "/product/backend", Apps: []*marathon.Application{{ID: "db"}}}},
+	}
+
+	nodes, err := buildDependencyGraph(descriptors)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"product/backend", "product/api", "product/web"}, dependencyOrder(nodes))
+	assert.Equal(t, 1, nodes["product/backend"].dependents)
+}
+
+func TestDependencyCycle(t *testing.T) {
+	_, err := buildDependencyGraph([]*Descriptor{appDescriptor("/a", "/b"), appDescriptor("/b", "/a")})
+	assert.EqualError(t, err, "Circular dependency detected: a -> b -> a")
+}
+
+func TestExecuteGraphSkipsDependentsOfFailures(t *testing.T) {
+	nodes, _ := buildDependencyGraph([]*Descriptor{
+		appDescriptor("/db"), appDescriptor("/api", "/db"), appDescriptor("/web", "/api"), appDescriptor("/worker"),
+	})
+
+	var mu sync.Mutex
+	deployed := []string{}
+	results := executeGraph(nodes, 2, func(n *depNode) error {
+		mu.Lock()
+		deployed = append(deployed, n.key)
+		mu.Unlock()
+		if n.key == "db" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	sort.Strings(deployed)
+	assert.Equal(t, []string{"db", "worker"}, deployed)
+	status := map[string]string{}
+	for _, r := range results {
+		status[r.ID] = r.Status
+	}
+	assert.Equal(t, map[string]string{"/db": DeployFailed, "/api": DeploySkipped, "/web": DeploySkipped, "/worker": DeploySuccess}, status)
+}
@@ -0,0 +1,249 @@
+package marathon
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/encoding"
+)
+
+// A parsed application or group descriptor
+type Descriptor struct {
+	Filename string
+	App      *marathon.Application
+	Group    *marathon.Group
+}
+
+func (d *Descriptor) IsApplication() bool {
+	return d.App != nil
+}
+
+func (d *Descriptor) ID() string {
+	if d.IsApplication() {
+		return d.App.ID
+	}
+	return d.Group.GroupID
+}
+
+// Renders {filename} through the template context for the specified {env}
+func renderDescriptor(ctx *TemplateContext, filename, rootDir, env string) (string, error) {
+	b := &bytes.Buffer{}
+	if err := ctx.TransformWithEnv(b, filename, rootDir, env); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Parses a rendered descriptor into an application or group substituting any ${PARAMS}
+func parseDescriptor(c marathon.Marathon, filename, rendered string, opts *marathon.CreateOptions) (*Descriptor, error) {
+	et, err := encoding.EncoderTypeFromExt(filename)
+	if err != nil {
+		return nil, err
+	}
+	ag, err := decodeAppOrGroup(et, rendered, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Descriptor{Filename: sourceName(filename)}
+	if ag.IsApplication() {
+		d.App, err = c.ParseApplicationFromString(strings.NewReader(rendered), et, opts)
+	} else {
+		d.Group, err = c.ParseGroupFromString(strings.NewReader(rendered), et, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Parses every document within a rendered descriptor file (see parseDescriptor).  In strict mode each
+// document is located within its part of the original file when the documents correspond
+func parseDescriptors(c marathon.Marathon, filename, rendered string, opts *marathon.CreateOptions) ([]*Descriptor, error) {
+	et, err := encoding.EncoderTypeFromExt(filename)
+	if err != nil {
+		return nil, err
+	}
+	docs := encoding.SplitDocuments(et, rendered)
+	if len(docs) <= 1 {
+		d, err := parseDescriptor(c, filename, rendered, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []*Descriptor{d}, nil
+	}
+
+	if opts == nil {
+		opts = &marathon.CreateOptions{}
+	}
+	var sources []*encoding.Document
+	if opts.Source != nil {
+		if sources = encoding.SplitDocuments(et, opts.Source.Content); len(sources) != len(docs) {
+			sources = nil
+		}
+	}
+
+	descriptors := []*Descriptor{}
+	for i, doc := range docs {
+		o := *opts
+		if opts.Source != nil {
+			src := &encoding.Source{Filename: opts.Source.Filename, LineOffset: doc.LineOffset}
+			if sources != nil {
+				src.Content, src.LineOffset = sources[i].Content, sources[i].LineOffset
+			}
+			o.Source = src
+		}
+		d, err := parseDescriptor(c, filename, doc.Content, &o)
+		if err != nil {
+			if _, located := err.(encoding.DecodeErrors); located {
+				return nil, err
+			}
+			return nil, fmt.Errorf("document %d: %s", i+1, err.Error())
+		}
+		descriptors = append(descriptors, d)
+	}
+	return descriptors, nil
+}
+
+// Returns true if {filename} is a YAML file containing multiple documents
+func isMultiDocument(filename string) bool {
+	et, err := encoding.EncoderTypeFromExt(filename)
+	if err != nil || et != encoding.YAML {
+		return false
+	}
+	data, err := ioutil.ReadFile(filename)
+	return err == nil && encoding.IsMultiDocument(et, string(data))
+}
+
+// Returns the value a document of a descriptor file is decoded into depending on whether it
+// declares an application or group
+func descriptorValue(doc string, et encoding.EncoderType) (interface{}, error) {
+	encoder, err := encoding.NewEncoder(et)
+	if err != nil {
+		return nil, err
+	}
+	ag := &marathon.AppOrGroup{}
+	if err := encoder.UnMarshalStr(doc, ag); err != nil {
+		return nil, err
+	}
+	if ag.IsApplication() {
+		return &marathon.Application{}, nil
+	}
+	return &marathon.Group{}, nil
+}
+
+// Decodes enough of the rendered descriptor to determine whether it is an application or group.  In
+// strict mode syntax errors are reported with their location within the original descriptor
+func decodeAppOrGroup(et encoding.EncoderType, rendered string, opts *marathon.CreateOptions) (*marathon.AppOrGroup, error) {
+	if opts != nil && opts.Strict {
+		var doc interface{}
+		if err := encoding.UnMarshalStrict(et, rendered, &doc, opts.Source); err != nil {
+			return nil, err
+		}
+	}
+	encoder, err := encoding.NewEncoder(et)
+	if err != nil {
+		return nil, err
+	}
+	ag := &marathon.AppOrGroup{}
+	if err := encoder.UnMarshalStr(rendered, ag); err != nil {
+		return nil, err
+	}
+	return ag, nil
+}
+
+// Returns the original (unrendered) content of {filename} used to locate strict parsing errors
+func descriptorSource(filename string) *encoding.Source {
+	b, _ := ioutil.ReadFile(filename)
+	return &encoding.Source{Filename: sourceName(filename), Content: string(b)}
+}
+
+// Returns all descriptor files (json or yaml) within {dir} and its sub directories ordered by
+// path.  Hidden files and directories as well as the {exclude} files are skipped
+func findDescriptors(dir string, exclude ...string) ([]string, error) {
+	excluded := map[string]bool{}
+	for _, e := range exclude {
+		if abs, err := filepath.Abs(e); err == nil {
+			excluded[abs] = true
+		}
+	}
+
+	files := []string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if _, err := encoding.EncoderTypeFromExt(path); err != nil {
+			return nil
+		}
+		if abs, err := filepath.Abs(path); err == nil && excluded[abs] {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// Reads an application or group descriptor from {filename} as is - no template or ${PARAMS} substitution
+// is performed.  Used for descriptors which were written by depcon such as snapshots
+func readDescriptor(filename string) (*Descriptor, error) {
+	encoder, err := encoding.NewEncoderFromFileExt(filename)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	ag := &marathon.AppOrGroup{}
+	if err := encoder.UnMarshalStr(string(data), ag); err != nil {
+		return nil, err
+	}
+
+	d := &Descriptor{Filename: filename}
+	if ag.IsApplication() {
+		d.App = &marathon.Application{}
+		err = encoder.UnMarshalStr(string(data), d.App)
+	} else {
+		d.Group = &marathon.Group{}
+		err = encoder.UnMarshalStr(string(data), d.Group)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Writes {v} to {filename} encoded according to the file extension (json or yaml)
+func writeDescriptor(filename string, v interface{}) error {
+	encoder, err := encoding.NewEncoderFromFileExt(filename)
+	if err != nil {
+		return err
+	}
+	data, err := encoder.MarshalIndent(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, []byte(data), 0600)
+}
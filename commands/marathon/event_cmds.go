@@ -1,9 +1,27 @@
 package marathon
 
 import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/utils"
 	"github.com/spf13/cobra"
 )
 
+const (
+	SUBSCRIPTION_FILE_FLAG = "file"
+	ActionAdd              = "add"
+	ActionRemove           = "remove"
+	ActionKeep             = "keep"
+)
+
+type SubscriptionChange struct {
+	CallbackURL string `json:"callbackUrl"`
+	Action      string `json:"action"`
+}
+
 var eventCmd = &cobra.Command{
 	Use:   "event",
 	Short: "Marathon event streaming and subscription management",
@@ -12,5 +30,144 @@ var eventCmd = &cobra.Command{
     See events's subcommands for available choices`,
 }
 
+var eventSubscriptionsCmd = &cobra.Command{
+	Use:     "subscriptions",
+	Aliases: []string{"subs"},
+	Short:   "Manage HTTP callback (webhook) event subscribers",
+	Long: `Manage HTTP callback (webhook) URLs which receive events from the Marathon event bus
+
+    See subscriptions's subcommands for available choices`,
+}
+
+var eventSubscriptionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all subscribed callback URLs",
+	Run: func(cmd *cobra.Command, args []string) {
+		v, e := client(cmd).ListEventSubscriptions()
+		cli.Output(templateFor(T_SUBSCRIPTIONS, v), e)
+	},
+}
+
+var eventSubscriptionsAddCmd = &cobra.Command{
+	Use:   "add [callbackUrl]",
+	Short: "Subscribes the [callbackUrl] to the event bus",
+	Run: func(cmd *cobra.Command, args []string) {
+		if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+			return
+		}
+		v, e := client(cmd).AddEventSubscription(args[0])
+		cli.Output(templateFor(T_SUBSCRIPTION_CHANGE, v), e)
+	},
+}
+
+var eventSubscriptionsRemoveCmd = &cobra.Command{
+	Use:   "remove [callbackUrl]",
+	Short: "Unsubscribes the [callbackUrl] from the event bus",
+	Run: func(cmd *cobra.Command, args []string) {
+		if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+			return
+		}
+		v, e := client(cmd).RemoveEventSubscription(args[0])
+		cli.Output(templateFor(T_SUBSCRIPTION_CHANGE, v), e)
+	},
+}
+
+var eventSubscriptionsSyncCmd = &cobra.Command{
+	Use:   "sync [callbackUrl ...]",
+	Short: "Declaratively sets the subscribed callback URLs for the current environment",
+	Long: `Ensures the subscribed callback URLs of the current environment match exactly the given [callbackUrl]'s
+and/or the URLs listed (one per line) within --file.  Missing URLs are added and any others are removed.
+
+    eg. depcon -e prod mar event subscriptions sync -f prod-webhooks.txt`,
+	Run: syncEventSubscriptions,
+}
+
 func init() {
+	eventSubscriptionsSyncCmd.Flags().StringP(SUBSCRIPTION_FILE_FLAG, "f", "", "File containing callback URLs (one per line)")
+	eventSubscriptionsSyncCmd.Flags().Bool(DRYRUN_FLAG, false, "Preview the changes - don't actually add or remove subscriptions")
+
+	eventSubscriptionsCmd.AddCommand(eventSubscriptionsListCmd, eventSubscriptionsAddCmd, eventSubscriptionsRemoveCmd, eventSubscriptionsSyncCmd)
+	eventCmd.AddCommand(eventSubscriptionsCmd)
+}
+
+func syncEventSubscriptions(cmd *cobra.Command, args []string) {
+	desired := []string{}
+	for _, u := range args {
+		desired = appendUnique(desired, u)
+	}
+
+	if filename, _ := cmd.Flags().GetString(SUBSCRIPTION_FILE_FLAG); filename != "" {
+		urls, err := readCallbackURLs(filename)
+		if err != nil {
+			exitWithError(err)
+		}
+		for _, u := range urls {
+			desired = appendUnique(desired, u)
+		}
+	}
+
+	if len(desired) == 0 {
+		cmd.Usage()
+		return
+	}
+
+	current, err := client(cmd).ListEventSubscriptions()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	dryrun, _ := cmd.Flags().GetBool(DRYRUN_FLAG)
+	changes := []*SubscriptionChange{}
+
+	for _, u := range desired {
+		if utils.StringInSlice(u, current.CallbackURLs) {
+			changes = append(changes, &SubscriptionChange{CallbackURL: u, Action: ActionKeep})
+			continue
+		}
+		if !dryrun {
+			if _, err := client(cmd).AddEventSubscription(u); err != nil {
+				exitWithError(err)
+			}
+		}
+		changes = append(changes, &SubscriptionChange{CallbackURL: u, Action: ActionAdd})
+	}
+
+	for _, u := range current.CallbackURLs {
+		if utils.StringInSlice(u, desired) {
+			continue
+		}
+		if !dryrun {
+			if _, err := client(cmd).RemoveEventSubscription(u); err != nil {
+				exitWithError(err)
+			}
+		}
+		changes = append(changes, &SubscriptionChange{CallbackURL: u, Action: ActionRemove})
+	}
+	cli.Output(templateFor(T_SUBSCRIPTION_SYNC, changes), nil)
+}
+
+func readCallbackURLs(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	urls := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
+
+func appendUnique(arr []string, value string) []string {
+	if utils.StringInSlice(value, arr) {
+		return arr
+	}
+	return append(arr, value)
 }
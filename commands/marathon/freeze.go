@@ -0,0 +1,90 @@
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ContainX/depcon/cliconfig"
+	"github.com/ContainX/depcon/marathon"
+	"github.com/spf13/viper"
+)
+
+const (
+	OVERRIDE_FREEZE_FLAG = "override-freeze"
+)
+
+var (
+	ErrorFreezeReasonRequired = errors.New("A --reason is required when using --override-freeze")
+)
+
+// Returned when a change is attempted against an environment during one of its freeze windows
+type FrozenError struct {
+	Env    string
+	Window *cliconfig.FreezeWindow
+}
+
+func (e *FrozenError) Error() string {
+	reason := e.Window.Reason
+	if reason == "" {
+		reason = "change freeze"
+	}
+	return fmt.Sprintf("Environment '%s' is frozen: %s (%s).  Use --%s --%s \"...\" to make changes anyway",
+		e.Env, reason, e.Window.String(), OVERRIDE_FREEZE_FLAG, REASON_FLAG)
+}
+
+// Returns the freeze window currently in effect for the environment {env} or nil
+func activeFreeze(env string) (*cliconfig.FreezeWindow, error) {
+	if configFile == nil {
+		return nil, nil
+	}
+	ce, err := configFile.GetEnvironment(env)
+	if err != nil {
+		return nil, nil
+	}
+	w, err := ce.ActiveFreeze(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("'%s': %s", env, err.Error())
+	}
+	return w, nil
+}
+
+// Returns a *FrozenError if the environment {env} is frozen and the freeze has not been overridden.  If
+// the freeze has been overridden the reason for the override is returned
+func checkFreeze(env string) (string, error) {
+	w, err := activeFreeze(env)
+	if err != nil || w == nil {
+		return "", err
+	}
+	if !viper.GetBool(OVERRIDE_FREEZE_FLAG) {
+		return "", &FrozenError{Env: env, Window: w}
+	}
+	reason := viper.GetString(REASON_FLAG)
+	if reason == "" {
+		return "", ErrorFreezeReasonRequired
+	}
+	return reason, nil
+}
+
+// Checks the freeze of the environment {c} targets.  Used by commands which bypass the journaled
+// client's own calls (eg. blue/green deployments)
+func guardFreeze(c marathon.Marathon) error {
+	if jc, ok := c.(*journaledClient); ok {
+		return jc.guard()
+	}
+	return nil
+}
+
+// Refuses changes while the client's environment is frozen.  Overrides are logged once per client and
+// recorded with each journal entry
+func (c *journaledClient) guard() error {
+	reason, err := checkFreeze(c.env)
+	if err != nil || reason == "" {
+		return err
+	}
+	c.overrideOnce.Do(func() {
+		c.override = reason
+		log.Warningf("Overriding the change freeze of '%s': %s", c.env, reason)
+	})
+	return nil
+}
@@ -0,0 +1,36 @@
+package marathon
+
+import (
+	"testing"
+
+	"github.com/ContainX/depcon/cliconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournaledClientRefusesChangesWhenFrozen(t *testing.T) {
+	defer func(c *cliconfig.ConfigFile) { configFile = c }(configFile)
+	configFile = &cliconfig.ConfigFile{Environments: map[string]*cliconfig.ConfigEnvironment{
+		"prod": {Freezes: []*cliconfig.FreezeWindow{{Cron: "* * * * *", Reason: "release week"}}},
+	}}
+
+	// the underlying client is never reached while frozen
+	c := &journaledClient{env: "prod"}
+	assertFrozen := func(err error) {
+		assert.IsType(t, &FrozenError{}, err)
+	}
+
+	_, err := c.KillAppTasks("/product/api", "", false)
+	assertFrozen(err)
+	_, err = c.KillAppTask("api.1", true)
+	assertFrozen(err)
+	assertFrozen(c.KillTasksAndScale("api.1", "api.2"))
+	_, err = c.DeleteDeployment("d1", false)
+	assertFrozen(err)
+	_, err = c.CancelAppDeployment("/product/api", false)
+	assertFrozen(err)
+	assertFrozen(c.ResetQueueDelay("/product/api"))
+	_, err = c.AddEventSubscription("http://hooks/marathon")
+	assertFrozen(err)
+	_, err = c.RemoveEventSubscription("http://hooks/marathon")
+	assertFrozen(err)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ContainX/depcon/pkg/encoding"
 	"github.com/spf13/cobra"
 	"os"
+	"strings"
 )
 
 var groupCmd = &cobra.Command{
@@ -45,7 +46,11 @@ var groupCreateCmd = &cobra.Command{
 var groupConvertFileCmd = &cobra.Command{
 	Use:   "convert [from.(json | yaml)] [to.(json | yaml)]",
 	Short: "Utilty to convert an group file from json to yaml or yaml to json.",
-	Run:   convertGroupFile,
+	Long: `Converts a group file from json to yaml or yaml to json.
+
+    Multi-document YAML files (documents separated by '---') may contain both applications and groups.
+    When converting them to json each document is written to its own file (eg. groups-1.json, groups-2.json)`,
+	Run: convertGroupFile,
 }
 
 func init() {
@@ -53,6 +58,7 @@ func init() {
 
 	// Destroy Flags
 	groupDestroyCmd.Flags().BoolP(WAIT_FLAG, "w", false, "Wait for destroy to complete")
+	addConfirmFlags(groupDestroyCmd)
 	// Create Flags
 	addDeployCreateFlags(groupCreateCmd)
 }
@@ -82,6 +88,7 @@ func destroyGroup(cmd *cobra.Command, args []string) {
 	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
 		return
 	}
+	confirmDestructive(cmd, ActionDestroy, args[0], true)
 	v, e := client(cmd).DestroyGroup(args[0])
 	cli.Output(templateFor(T_DEPLOYMENT_ID, v), e)
 }
@@ -98,9 +105,10 @@ func convertGroupFile(cmd *cobra.Command, args []string) {
 	if cli.EvalPrintUsage(Usage(cmd), args, 2) {
 		os.Exit(1)
 	}
-	if err := encoding.ConvertFile(args[0], args[1], &marathon.Groups{}); err != nil {
+	written, err := encoding.ConvertDocuments(args[0], args[1], descriptorValue)
+	if err != nil {
 		cli.Output(nil, err)
 		os.Exit(1)
 	}
-	fmt.Printf("Source file %s has been re-written into new format in %s\n\n", args[0], args[1])
+	fmt.Printf("Source file %s has been re-written into new format in %s\n\n", args[0], strings.Join(written, ", "))
 }
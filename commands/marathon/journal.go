@@ -0,0 +1,353 @@
+package marathon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/ContainX/depcon/cliconfig"
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/journal"
+	"github.com/ContainX/depcon/utils"
+)
+
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionRollback  = "rollback"
+	ActionScale     = "scale"
+	ActionPause     = "pause"
+	ActionRestart   = "restart"
+	ActionDestroy   = "destroy"
+	ActionBlueGreen = "bluegreen"
+
+	ActionKill        = "kill"
+	ActionCancel      = "cancel"
+	ActionResetDelay  = "reset-delay"
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+// A Marathon client which records every mutating call within the local journal and refuses them while
+// the environment is frozen.  All other calls are passed through as is
+type journaledClient struct {
+	marathon.Marathon
+	env     string
+	journal *journal.Journal
+
+	// reason given for overriding the environment's change freeze
+	override     string
+	overrideOnce sync.Once
+}
+
+// Wraps {c} so that changes made against the environment {env} are journaled
+func journaled(c marathon.Marathon, env string) marathon.Marathon {
+	return &journaledClient{Marathon: c, env: env, journal: journal.New(cliconfig.ConfigDir())}
+}
+
+// Returns the underlying client for operations which are journaled as a whole rather than per call
+func unjournaled(c marathon.Marathon) marathon.Marathon {
+	if jc, ok := c.(*journaledClient); ok {
+		return jc.Marathon
+	}
+	return c
+}
+
+// Records an {action} performed outside of the client's own calls (eg. a blue/green deployment)
+func journalAction(c marathon.Marathon, action, id, hash string, start time.Time, err error) {
+	if jc, ok := c.(*journaledClient); ok {
+		jc.record(action, id, hash, "", start, err)
+	}
+}
+
+func (c *journaledClient) record(action, id, hash, deploymentID string, start time.Time, err error) {
+	e := &journal.Entry{
+		Env:            c.env,
+		ID:             id,
+		Action:         action,
+		DeploymentID:   deploymentID,
+		DescriptorHash: hash,
+		Outcome:        journal.OutcomeSuccess,
+		Elapsed:        utils.ElapsedStr(time.Since(start)),
+		FreezeOverride: c.override,
+	}
+	if err != nil {
+		e.Outcome, e.Error = journal.OutcomeFailed, err.Error()
+	}
+	if jerr := c.journal.Append(e); jerr != nil {
+		log.Warningf("Unable to write to journal %s: %s", c.journal.Filename(), jerr.Error())
+	}
+}
+
+func (c *journaledClient) CreateApplicationFromFile(filename string, opts *marathon.CreateOptions) (*marathon.Application, error) {
+	if opts == nil || !opts.DryRun {
+		if err := c.guard(); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	app, err := c.Marathon.CreateApplicationFromFile(filename, opts)
+	if opts == nil || !opts.DryRun {
+		c.record(ActionCreate, appID(app, filename), fileHash(filename), appDeploymentID(app), start, err)
+	}
+	return app, err
+}
+
+func (c *journaledClient) CreateApplicationFromString(filename string, appstr string, opts *marathon.CreateOptions) (*marathon.Application, error) {
+	if opts == nil || !opts.DryRun {
+		if err := c.guard(); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	app, err := c.Marathon.CreateApplicationFromString(filename, appstr, opts)
+	if opts == nil || !opts.DryRun {
+		c.record(ActionCreate, appID(app, filename), journal.Hash([]byte(appstr)), appDeploymentID(app), start, err)
+	}
+	return app, err
+}
+
+func (c *journaledClient) CreateApplication(app *marathon.Application, wait, force bool) (*marathon.Application, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	id, hash := app.ID, valueHash(app)
+	result, err := c.Marathon.CreateApplication(app, wait, force)
+	c.record(ActionCreate, id, hash, appDeploymentID(result), start, err)
+	return result, err
+}
+
+func (c *journaledClient) UpdateApplication(app *marathon.Application, wait, force bool) (*marathon.Application, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	action := ActionUpdate
+	if app.Version != "" {
+		action = ActionRollback
+	}
+	id, hash := app.ID, valueHash(app)
+	result, err := c.Marathon.UpdateApplication(app, wait, force)
+	c.record(action, id, hash, "", start, err)
+	return result, err
+}
+
+func (c *journaledClient) DestroyApplication(id string) (*marathon.DeploymentID, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.DestroyApplication(id)
+	c.record(ActionDestroy, id, "", deploymentID(result), start, err)
+	return result, err
+}
+
+func (c *journaledClient) RestartApplication(id string, force bool) (*marathon.DeploymentID, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.RestartApplication(id, force)
+	c.record(ActionRestart, id, "", deploymentID(result), start, err)
+	return result, err
+}
+
+func (c *journaledClient) ScaleApplication(id string, instances int) (*marathon.DeploymentID, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.ScaleApplication(id, instances)
+	c.record(ActionScale, id, "", deploymentID(result), start, err)
+	return result, err
+}
+
+func (c *journaledClient) PauseApplication(id string) (*marathon.DeploymentID, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.PauseApplication(id)
+	c.record(ActionPause, id, "", deploymentID(result), start, err)
+	return result, err
+}
+
+func (c *journaledClient) CreateGroupFromFile(filename string, opts *marathon.CreateOptions) (*marathon.Group, error) {
+	if opts == nil || !opts.DryRun {
+		if err := c.guard(); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	group, err := c.Marathon.CreateGroupFromFile(filename, opts)
+	if opts == nil || !opts.DryRun {
+		c.record(ActionCreate, groupID(group, filename), fileHash(filename), "", start, err)
+	}
+	return group, err
+}
+
+func (c *journaledClient) CreateGroupFromString(filename string, grpstr string, opts *marathon.CreateOptions) (*marathon.Group, error) {
+	if opts == nil || !opts.DryRun {
+		if err := c.guard(); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	group, err := c.Marathon.CreateGroupFromString(filename, grpstr, opts)
+	if opts == nil || !opts.DryRun {
+		c.record(ActionCreate, groupID(group, filename), journal.Hash([]byte(grpstr)), "", start, err)
+	}
+	return group, err
+}
+
+func (c *journaledClient) CreateGroup(group *marathon.Group, wait, force bool) (*marathon.Group, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	id, hash := group.GroupID, valueHash(group)
+	result, err := c.Marathon.CreateGroup(group, wait, force)
+	c.record(ActionCreate, id, hash, "", start, err)
+	return result, err
+}
+
+func (c *journaledClient) DestroyGroup(id string) (*marathon.DeploymentID, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.DestroyGroup(id)
+	c.record(ActionDestroy, id, "", deploymentID(result), start, err)
+	return result, err
+}
+
+func (c *journaledClient) KillAppTasks(id string, host string, scale bool) ([]*marathon.Task, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.KillAppTasks(id, host, scale)
+	c.record(ActionKillAll, id, "", "", start, err)
+	return result, err
+}
+
+func (c *journaledClient) KillAppTask(taskId string, scale bool) (*marathon.Task, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.KillAppTask(taskId, scale)
+	c.record(ActionKill, taskId, "", "", start, err)
+	return result, err
+}
+
+func (c *journaledClient) KillTasksAndScale(ids ...string) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := c.Marathon.KillTasksAndScale(ids...)
+	for _, id := range ids {
+		c.record(ActionKill, id, "", "", start, err)
+	}
+	return err
+}
+
+func (c *journaledClient) DeleteDeployment(id string, force bool) (*marathon.DeploymentID, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.DeleteDeployment(id, force)
+	c.record(ActionCancel, id, "", deploymentID(result), start, err)
+	return result, err
+}
+
+func (c *journaledClient) CancelAppDeployment(appId string, matchPrefix bool) (*marathon.DeploymentID, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.CancelAppDeployment(appId, matchPrefix)
+	if result != nil || err != nil {
+		// nothing is recorded when no deployment matched
+		c.record(ActionCancel, appId, "", deploymentID(result), start, err)
+	}
+	return result, err
+}
+
+func (c *journaledClient) ResetQueueDelay(id string) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := c.Marathon.ResetQueueDelay(id)
+	c.record(ActionResetDelay, id, "", "", start, err)
+	return err
+}
+
+func (c *journaledClient) AddEventSubscription(callbackUrl string) (*marathon.EventSubscriptionChange, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.AddEventSubscription(callbackUrl)
+	c.record(ActionSubscribe, callbackUrl, "", "", start, err)
+	return result, err
+}
+
+func (c *journaledClient) RemoveEventSubscription(callbackUrl string) (*marathon.EventSubscriptionChange, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := c.Marathon.RemoveEventSubscription(callbackUrl)
+	c.record(ActionUnsubscribe, callbackUrl, "", "", start, err)
+	return result, err
+}
+
+func valueHash(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return journal.Hash(b)
+}
+
+func fileHash(filename string) string {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	return journal.Hash(b)
+}
+
+func deploymentID(d *marathon.DeploymentID) string {
+	if d == nil {
+		return ""
+	}
+	return d.DeploymentID
+}
+
+func appDeploymentID(app *marathon.Application) string {
+	if app == nil || len(app.DeploymentID) == 0 {
+		return ""
+	}
+	return app.DeploymentID[0]["id"]
+}
+
+func appID(app *marathon.Application, fallback string) string {
+	if app == nil {
+		return fallback
+	}
+	return app.ID
+}
+
+func groupID(group *marathon.Group, fallback string) string {
+	if group == nil {
+		return fallback
+	}
+	return group.GroupID
+}
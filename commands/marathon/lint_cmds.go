@@ -0,0 +1,112 @@
+package marathon
+
+import (
+	"os"
+
+	"github.com/ContainX/depcon/marathon/lint"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	DISABLE_FLAG   = "disable"
+	BLUEGREEN_FLAG = "bluegreen"
+	RULES_FLAG     = "rules"
+
+	// Rule reported when a descriptor cannot be rendered or parsed
+	RuleParse = "parse"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [file(.json | .yaml)]...",
+	Short: "Checks descriptors against a set of best practice rules",
+	Long: `Renders and parses each descriptor the same way 'deploy create' does and checks every application against
+the built-in rules.  Each issue is reported with its rule id, severity and the path of the offending field.
+Use -o json for machine readable output.  The command exits with a non-zero status if any errors are found.
+
+With --strict unknown fields are reported as parse errors along with their line and closest valid field.
+
+Rules can be disabled with --disable or for everyone via "lint": { "disabled": [...] } within the configuration.
+
+    eg. depcon mar lint ./apps/*.json --disable port-name`,
+	Run: lintDescriptors,
+}
+
+func init() {
+	addRenderFlags(lintCmd)
+	lintCmd.Flags().StringSlice(DISABLE_FLAG, nil, "Rule ids which should not be checked")
+	lintCmd.Flags().Bool(BLUEGREEN_FLAG, false, "Check every app for blue/green deployment labels (default: only apps with HAPROXY_* labels)")
+	lintCmd.Flags().Bool(RULES_FLAG, false, "List the available rules")
+}
+
+func lintDescriptors(cmd *cobra.Command, args []string) {
+	if rules, _ := cmd.Flags().GetBool(RULES_FLAG); rules {
+		cli.Output(templateFor(T_LINT_RULES, lint.Rules()), nil)
+		return
+	}
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+
+	files, err := expandDescriptorArgs(args)
+	if err != nil {
+		exitWithError(err)
+	}
+	defer removeSources()
+	if files, err = resolveSources(cmd, files); err != nil {
+		exitWithError(err)
+	}
+
+	issues := lintFiles(cmd, files)
+	cli.Output(templateFor(T_LINT, issues), nil)
+	if lint.HasErrors(issues) {
+		os.Exit(1)
+	}
+}
+
+// Lints {files} for the current environment.  Files which cannot be parsed are reported as a parse error
+func lintFiles(cmd *cobra.Command, files []string) []*lint.Issue {
+	opts := &lint.Options{}
+	opts.Disabled, _ = cmd.Flags().GetStringSlice(DISABLE_FLAG)
+	opts.BlueGreen, _ = cmd.Flags().GetBool(BLUEGREEN_FLAG)
+	if configFile != nil && configFile.Lint != nil {
+		opts.Disabled = append(opts.Disabled, configFile.Lint.Disabled...)
+	}
+
+	env := viper.GetString(ENV_NAME)
+	params := envParams(env)
+
+	s := deploySettingsFromFlags(cmd)
+	s.enforcePolicies = false
+	c := client(cmd)
+	linter := lint.New(opts)
+	issues := []*lint.Issue{}
+
+	for _, f := range files {
+		descriptors, err := s.load(c, []string{f}, env, params)
+		if errs, ok := err.(encoding.DecodeErrors); ok {
+			for _, de := range errs {
+				issues = append(issues, &lint.Issue{File: de.Location(), Rule: RuleParse, Severity: lint.SeverityError, Path: de.Path, Message: de.Detail()})
+			}
+			continue
+		} else if err != nil {
+			issues = append(issues, &lint.Issue{File: f, Rule: RuleParse, Severity: lint.SeverityError, Message: err.Error()})
+			continue
+		}
+		for _, d := range descriptors {
+			var found []*lint.Issue
+			if d.IsApplication() {
+				found = linter.LintApplication(d.App)
+			} else {
+				found = linter.LintGroup(d.Group)
+			}
+			for _, i := range found {
+				i.File = sourceName(f)
+			}
+			issues = append(issues, found...)
+		}
+	}
+	return issues
+}
@@ -0,0 +1,231 @@
+package marathon
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/pkg/journal"
+	"github.com/ContainX/depcon/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	LOCK_TTL_FLAG  = "lock-ttl"
+	LOCK_WAIT_FLAG = "lock-wait"
+	NO_LOCK_FLAG   = "no-lock"
+
+	DefaultLockTTL = time.Minute * 30
+)
+
+var (
+	exitHooks   []func()
+	exitHooksMu sync.Mutex
+
+	lockOwnerToken string
+	lockOwnerOnce  sync.Once
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Advisory deployment locks which prevent concurrent rollouts of the same app or group",
+	Long: `Manage advisory deployment locks.  A lock on an app or group also covers its parent groups and children.
+Locks are stored within Marathon (as marker apps under ` + marathon.LockGroup + `) along with their owner and
+expiry so they are shared by everyone deploying to the environment.
+
+A lock acquired with 'lock acquire' is held by you (user@host) until released and is reused by your own deployments.
+'deploy create' and 'app bluegreen' otherwise acquire and release locks automatically.  Those locks belong to the
+single run which acquired them so concurrent runs on the same host exclude each other.
+
+See lock's subcommands for available choices`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var lockListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all deployment locks",
+	Run: func(cmd *cobra.Command, args []string) {
+		locks, err := marathon.ListLocks(unjournaled(client(cmd)))
+		cli.Output(templateFor(T_LOCKS, locks), err)
+	},
+}
+
+var lockAcquireCmd = &cobra.Command{
+	Use:   "acquire [appOrGroupId]",
+	Short: "Acquires a lock on [appOrGroupId] preventing others from deploying it",
+	Run: func(cmd *cobra.Command, args []string) {
+		if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+			return
+		}
+		ttl, _ := cmd.Flags().GetDuration(LOCK_TTL_FLAG)
+		wait, _ := cmd.Flags().GetDuration(LOCK_WAIT_FLAG)
+		reason := viper.GetString(REASON_FLAG)
+
+		lock, err := marathon.WaitForLock(unjournaled(client(cmd)), args[0], lockHolder(), reason, ttl, wait)
+		if err != nil {
+			exitWithError(err)
+		}
+		cli.Output(templateFor(T_LOCKS, []*marathon.Lock{lock}), nil)
+	},
+}
+
+var lockReleaseCmd = &cobra.Command{
+	Use:   "release [appOrGroupId]",
+	Short: "Releases your lock on [appOrGroupId]",
+	Run: func(cmd *cobra.Command, args []string) {
+		if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+			return
+		}
+		if err := marathon.ReleaseLock(unjournaled(client(cmd)), args[0], lockHolder()); err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("Lock on '%s' has been released\n", args[0])
+	},
+}
+
+var lockBreakCmd = &cobra.Command{
+	Use:   "break [appOrGroupId]",
+	Short: "Forcibly removes the lock on [appOrGroupId] regardless of its owner",
+	Run: func(cmd *cobra.Command, args []string) {
+		if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+			return
+		}
+		if err := marathon.BreakLock(unjournaled(client(cmd)), args[0]); err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("Lock on '%s' has been broken\n", args[0])
+	},
+}
+
+func init() {
+	lockCmd.AddCommand(lockListCmd, lockAcquireCmd, lockReleaseCmd, lockBreakCmd)
+
+	lockAcquireCmd.Flags().Duration(LOCK_TTL_FLAG, DefaultLockTTL, "How long the lock is held before it expires (ex. 30m | 2h)")
+	lockAcquireCmd.Flags().Duration(LOCK_WAIT_FLAG, time.Duration(0), "Max duration to wait if the app or group is locked by someone else")
+}
+
+func addLockFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(NO_LOCK_FLAG, false, "Do not acquire deployment locks")
+	cmd.Flags().Duration(LOCK_TTL_FLAG, DefaultLockTTL, "Expiry of the deployment lock in case depcon does not release it (ex. 30m)")
+	cmd.Flags().Duration(LOCK_WAIT_FLAG, time.Duration(0), "Max duration to wait for a deployment lock held by someone else (default: fail immediately)")
+}
+
+// Acquires deployment locks on {ids} unless --no-lock was specified (or the command has no lock flags).  Each invocation
+// owns its locks so concurrent runs by the same user exclude each other.  Ids covered by a lock the user acquired
+// with 'lock acquire' are left alone.  The returned func releases the acquired locks and also runs if the command
+// exits early with an error
+func acquireDeployLocks(cmd *cobra.Command, c marathon.Marathon, ids []string) (func(), error) {
+	if noLock, err := cmd.Flags().GetBool(NO_LOCK_FLAG); noLock || err != nil {
+		return func() {}, nil
+	}
+	ttl, _ := cmd.Flags().GetDuration(LOCK_TTL_FLAG)
+	wait, _ := cmd.Flags().GetDuration(LOCK_WAIT_FLAG)
+
+	reason := viper.GetString(REASON_FLAG)
+	if reason == "" {
+		reason = "deploy"
+	}
+
+	c = unjournaled(c)
+	owner := lockOwner()
+	acquired := []string{}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			for _, id := range acquired {
+				if err := marathon.ReleaseLock(c, id, owner); err != nil {
+					log.Warningf("Unable to release lock on '%s': %s", id, err.Error())
+				}
+			}
+		})
+	}
+
+	held, err := marathon.ListLocks(c)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+	for _, id := range sorted {
+		if handedOff(held, id) {
+			continue
+		}
+		if _, err := marathon.WaitForLock(c, id, owner, reason, ttl, wait); err != nil {
+			release()
+			return nil, err
+		}
+		acquired = append(acquired, id)
+	}
+
+	onExit(release)
+	return release, nil
+}
+
+// Returns the id declared within the descriptor {filename} or an empty string if it could not be read
+func descriptorFileID(filename string) string {
+	d, err := readDescriptor(filename)
+	if err != nil {
+		return ""
+	}
+	return d.ID()
+}
+
+// Returns true if {id} or a parent is locked by the current user through 'lock acquire'
+func handedOff(locks []*marathon.Lock, id string) bool {
+	target := "/" + utils.TrimRootPath(id)
+	for _, l := range locks {
+		if l.Owner != lockHolder() || l.Expired() {
+			continue
+		}
+		if t := "/" + utils.TrimRootPath(l.Target); t == target || strings.HasPrefix(target, t+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// The owner recorded on locks acquired explicitly by the current user with 'lock acquire' (eg. jdoe@ci-1)
+func lockHolder() string {
+	host, _ := os.Hostname()
+	return journal.CurrentUser() + "@" + host
+}
+
+// The owner recorded on locks acquired while deploying.  Unique to this invocation (eg. jdoe@ci-1/4312-9f2a01)
+// so two runs on the same host never share a lock
+func lockOwner() string {
+	lockOwnerOnce.Do(func() {
+		nonce := make([]byte, 3)
+		rand.Read(nonce)
+		lockOwnerToken = fmt.Sprintf("%s/%d-%s", lockHolder(), os.Getpid(), hex.EncodeToString(nonce))
+	})
+	return lockOwnerToken
+}
+
+// Registers {fn} to run before the command exits due to an error
+func onExit(fn func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+func runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := exitHooks
+	exitHooks = nil
+	exitHooksMu.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+}
@@ -0,0 +1,30 @@
+package marathon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockOwner(t *testing.T) {
+	assert.True(t, strings.HasPrefix(lockOwner(), lockHolder()+"/"))
+	assert.Equal(t, lockOwner(), lockOwner())
+}
+
+func TestHandedOff(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	locks := []*marathon.Lock{
+		{Target: "/product", Owner: lockHolder(), Expires: expires},
+		{Target: "/reporting/etl", Owner: "other@host", Expires: expires},
+		{Target: "/infra", Owner: lockHolder() + "/1234-abcdef", Expires: expires},
+	}
+
+	assert.True(t, handedOff(locks, "/product"))
+	assert.True(t, handedOff(locks, "product/api"))
+	assert.False(t, handedOff(locks, "/reporting/etl"))
+	assert.False(t, handedOff(locks, "/infra/db"), "locks of another run are not reused")
+	assert.False(t, handedOff(locks, "/productx"))
+}
@@ -1,6 +1,8 @@
 package marathon
 
 import (
+	"fmt"
+
 	"github.com/ContainX/depcon/cliconfig"
 	"github.com/ContainX/depcon/marathon"
 	"github.com/spf13/cobra"
@@ -18,6 +20,11 @@ const (
 	INSECURE_FLAG  string = "insecure"
 	ENV_NAME       string = "env_name"
 	DRYRUN_FLAG    string = "dry-run"
+	REASON_FLAG    string = "reason"
+
+	OVERRIDE_GUARDRAILS_FLAG string = "override-guardrails"
+	STRICT_PARSE_FLAG        string = "strict"
+	FAIL_UNSUPPORTED_FLAG    string = "fail-unsupported"
 )
 
 var (
@@ -30,6 +37,9 @@ var (
 	}
 	marathonClient marathon.Marathon
 	configFile     *cliconfig.ConfigFile
+
+	// The depcon version stamped within deployment metadata labels
+	Version string
 )
 
 // Associates the marathon service to the given command
@@ -54,27 +64,60 @@ func AddJailedMarathonToCmd(rc *cobra.Command, c *cliconfig.ConfigFile) {
 func associateServiceCommands(parent *cobra.Command) {
 	parent.PersistentFlags().Bool(INSECURE_FLAG, false, "Skips Insecure TLS/HTTPS Certificate checks")
 	viper.BindPFlag(INSECURE_FLAG, parent.PersistentFlags().Lookup(INSECURE_FLAG))
+	parent.PersistentFlags().Bool(FAIL_UNSUPPORTED_FLAG, false, "Fail instead of warn when a descriptor uses features the Marathon server version does not support")
+	viper.BindPFlag(FAIL_UNSUPPORTED_FLAG, parent.PersistentFlags().Lookup(FAIL_UNSUPPORTED_FLAG))
+	parent.PersistentFlags().Bool(STRICT_PARSE_FLAG, false, "Reject descriptors declaring unknown fields and report parse errors with their file, line and column")
+	viper.BindPFlag(STRICT_PARSE_FLAG, parent.PersistentFlags().Lookup(STRICT_PARSE_FLAG))
+	parent.PersistentFlags().Bool(OVERRIDE_FREEZE_FLAG, false, "Allow changes during a change freeze of the environment (requires --reason)")
+	viper.BindPFlag(OVERRIDE_FREEZE_FLAG, parent.PersistentFlags().Lookup(OVERRIDE_FREEZE_FLAG))
+	parent.PersistentFlags().Bool(OVERRIDE_GUARDRAILS_FLAG, false, "Deploy even if apps or groups violate the environment guardrails (max instances, resources, labels, registries)")
+	viper.BindPFlag(OVERRIDE_GUARDRAILS_FLAG, parent.PersistentFlags().Lookup(OVERRIDE_GUARDRAILS_FLAG))
+	parent.PersistentFlags().String(REASON_FLAG, "", "Why the change is being made (recorded with freeze overrides and locks)")
+	viper.BindPFlag(REASON_FLAG, parent.PersistentFlags().Lookup(REASON_FLAG))
+	parent.PersistentFlags().String(SOURCE_TOKEN_FLAG, "", "Bearer token sent when fetching descriptors and template contexts from https urls")
+	viper.BindPFlag(SOURCE_TOKEN_FLAG, parent.PersistentFlags().Lookup(SOURCE_TOKEN_FLAG))
+	parent.PersistentFlags().String(SOURCE_USER_FLAG, "", "Basic auth credentials (user:password) used when fetching descriptors and template contexts from https urls")
+	viper.BindPFlag(SOURCE_USER_FLAG, parent.PersistentFlags().Lookup(SOURCE_USER_FLAG))
 
-	parent.AddCommand(appCmd, groupCmd, deployCmd, taskCmd, eventCmd, serverCmd)
+	parent.AddCommand(appCmd, groupCmd, deployCmd, taskCmd, eventCmd, serverCmd, applyCmd, compareCmd, snapshotCmd, lockCmd, lintCmd, policyCmd)
 }
 
 func client(c *cobra.Command) marathon.Marathon {
 	if marathonClient == nil {
-		envName := viper.GetString(ENV_NAME)
-		insecure := viper.GetBool(INSECURE_FLAG)
-		mc := *configFile.Environments[envName].Marathon
-		opts := &marathon.MarathonOptions{}
-		if timeout, err := c.Flags().GetDuration(TIMEOUT_FLAG); err == nil {
-			opts.WaitTimeout = timeout
+		mc, err := clientForEnv(c, viper.GetString(ENV_NAME))
+		if err != nil {
+			exitWithError(err)
 		}
-		opts.TLSAllowInsecure = insecure
-
-		marathonClient = marathon.NewMarathonClientWithOpts(mc.HostUrl, mc.Username, mc.Password, mc.Token, opts)
-
+		marathonClient = mc
 	}
 	return marathonClient
 }
 
+// Creates a client for the named environment within the configuration.  Used by commands which operate
+// against multiple environments at once
+func clientForEnv(c *cobra.Command, envName string) (marathon.Marathon, error) {
+	env, err := configFile.GetEnvironment(envName)
+	if err != nil {
+		return nil, fmt.Errorf("'%s': %s", envName, err.Error())
+	}
+	mc := *env.Marathon
+	opts := &marathon.MarathonOptions{}
+	if timeout, err := c.Flags().GetDuration(TIMEOUT_FLAG); err == nil {
+		opts.WaitTimeout = timeout
+	}
+	opts.TLSAllowInsecure = viper.GetBool(INSECURE_FLAG)
+	opts.FailOnUnsupported = viper.GetBool(FAIL_UNSUPPORTED_FLAG)
+	if env.Guardrails != nil {
+		if viper.GetBool(OVERRIDE_GUARDRAILS_FLAG) {
+			log.Warningf("Overriding the guardrails of '%s'", envName)
+		} else {
+			opts.Guardrails = env.Guardrails
+		}
+	}
+
+	return journaled(marathon.NewMarathonClientWithOpts(mc.HostUrl, mc.Username, mc.Password, mc.Token, opts), envName), nil
+}
+
 func Usage(c *cobra.Command) func() error {
 
 	return func() error {
@@ -0,0 +1,93 @@
+package marathon
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/journal"
+)
+
+const (
+	// Marathon feature within an environment controlling the deployment metadata labels.  "false" disables
+	// them, "all" includes everything, otherwise a comma separated list of the metadata to include
+	// (default: version,git,checksum).  Marathon restarts the tasks of an app whenever its labels change so
+	// user, host and timestamp, which change on every deployment, must be enabled explicitly
+	//   eg. "features": { "metadata": "user,git,checksum,timestamp" }
+	FeatureMetadata = "metadata"
+
+	MetadataUser      = "user"
+	MetadataHost      = "host"
+	MetadataVersion   = "version"
+	MetadataGit       = "git"
+	MetadataChecksum  = "checksum"
+	MetadataTimestamp = "timestamp"
+)
+
+// Metadata stamped when the environment does not configure any.  Values which change on every deployment
+// are left out so redeploying an unchanged descriptor does not restart its tasks
+var defaultMetadata = []string{MetadataVersion, MetadataGit, MetadataChecksum}
+
+// Builds the deployment metadata stamped on apps deployed to {env} from the {descriptor} file.  The git
+// commit and branch are those of the repository containing the descriptor.  Returns nil if metadata
+// has been disabled for the environment
+func deployMetadata(env, descriptor string) *marathon.DeployMetadata {
+	enabled := metadataEnabled(env)
+	if enabled == nil {
+		return nil
+	}
+
+	m := &marathon.DeployMetadata{}
+	if enabled(MetadataUser) {
+		m.User = journal.CurrentUser()
+	}
+	if enabled(MetadataHost) {
+		m.Host, _ = os.Hostname()
+	}
+	if enabled(MetadataVersion) {
+		m.Version = Version
+	}
+	if enabled(MetadataGit) && descriptor != "" {
+		dir := filepath.Dir(descriptor)
+		m.GitCommit = gitRevParse(dir, "HEAD")
+		m.GitBranch = gitRevParse(dir, "--abbrev-ref", "HEAD")
+	}
+	if enabled(MetadataTimestamp) {
+		m.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	m.ComputeChecksum = enabled(MetadataChecksum)
+	return m
+}
+
+// Returns a func reporting whether the named metadata is enabled for {env} or nil if disabled entirely
+func metadataEnabled(env string) func(name string) bool {
+	setting := envFeature(env, FeatureMetadata)
+
+	switch strings.ToLower(setting) {
+	case "", "true":
+		setting = strings.Join(defaultMetadata, ",")
+	case "all":
+		return func(string) bool { return true }
+	case "false", "none", "off":
+		return nil
+	}
+
+	names := map[string]bool{}
+	for _, n := range strings.Split(setting, ",") {
+		names[strings.ToLower(strings.TrimSpace(n))] = true
+	}
+	return func(name string) bool { return names[name] }
+}
+
+func gitRevParse(dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"rev-parse"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
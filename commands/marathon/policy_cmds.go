@@ -0,0 +1,139 @@
+package marathon
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/marathon/policy"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	POLICY_FLAG = "policy"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Deployment policies evaluated before 'deploy create' and 'app bluegreen'",
+	Long: `Deployment policies are assertions against the fields of every application (or group) being deployed.
+Policy files are listed per environment within the configuration ("policies": ["./prod-policies.yaml"]).
+Relative paths are resolved from the directory of the configuration file.
+A violated 'deny' policy stops the deployment while a violated 'warn' policy is logged.
+
+    policies:
+      - name: pinned-images
+        action: deny
+        assert:
+          - { path: container.docker.image, op: matches, value: ":[0-9]" }
+      - name: prod-instances
+        action: warn
+        when:
+          - { path: labels.tier, op: eq, value: critical }
+        assert:
+          - { path: instances, op: gte, value: 3 }
+
+See policy's subcommands for available choices`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var policyTestCmd = &cobra.Command{
+	Use:   "test [file(.json | .yaml)]...",
+	Short: "Evaluates policies against descriptors without deploying them",
+	Long: `Renders and parses each descriptor the same way 'deploy create' does and evaluates the policies of the
+current environment (or those specified with --policy) against it.  Nothing is sent to Marathon.  The
+command exits with a non-zero status if any deny policy is violated.
+
+    eg. depcon -e prod mar policy test ./apps/*.yaml
+        depcon mar policy test app.json --policy ./policies/strict.yaml`,
+	Run: testPolicies,
+}
+
+func init() {
+	policyCmd.AddCommand(policyTestCmd)
+
+	policyTestCmd.Flags().StringSlice(POLICY_FLAG, nil, "Policy file(s) to evaluate (default: the policies of the environment)")
+	addRenderFlags(policyTestCmd)
+}
+
+// Returns the policies of the environment {env} or nil if none are configured
+func envPolicies(env string) (*policy.PolicySet, error) {
+	if configFile == nil {
+		return nil, nil
+	}
+	ce, err := configFile.GetEnvironment(env)
+	if err != nil || len(ce.Policies) == 0 {
+		return nil, nil
+	}
+	// relative paths are declared relative to the configuration file
+	return policy.LoadFrom(filepath.Dir(configFile.Filename()), ce.Policies...)
+}
+
+// Returns the verifier enforcing the policies of the environment {env} or nil if none are configured
+func policyVerifier(env string) (marathon.Verifier, error) {
+	ps, err := envPolicies(env)
+	if err != nil || ps == nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+func testPolicies(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+
+	env := viper.GetString(ENV_NAME)
+	ps, err := envPolicies(env)
+	if files, _ := cmd.Flags().GetStringSlice(POLICY_FLAG); len(files) > 0 {
+		ps, err = policy.Load(files...)
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+	if ps == nil {
+		ps = &policy.PolicySet{}
+	}
+
+	files, err := expandDescriptorArgs(args)
+	if err != nil {
+		exitWithError(err)
+	}
+	defer removeSources()
+	if files, err = resolveSources(cmd, files); err != nil {
+		exitWithError(err)
+	}
+
+	s := deploySettingsFromFlags(cmd)
+	s.enforcePolicies = false
+	descriptors, err := s.load(client(cmd), files, env, envParams(env))
+	if err != nil {
+		exitWithError(err)
+	}
+
+	violations := []*policy.Violation{}
+	for _, d := range descriptors {
+		var found []*policy.Violation
+		if d.IsApplication() {
+			found, err = ps.EvaluateApplication(d.App)
+		} else {
+			found, err = ps.EvaluateGroup(d.Group)
+		}
+		if err != nil {
+			exitWithError(err)
+		}
+		for _, v := range found {
+			v.File = d.Filename
+		}
+		violations = append(violations, found...)
+	}
+
+	cli.Output(templateFor(T_POLICY_VIOLATIONS, violations), nil)
+	if policy.Denied(violations) {
+		os.Exit(1)
+	}
+}
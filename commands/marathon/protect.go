@@ -0,0 +1,143 @@
+package marathon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// Feature which marks an environment as protected requiring confirmation of destructive actions
+	FeatureProtected = "protected"
+	// Feature listing the roles (comma separated) which may not perform destructive actions at all
+	FeatureForbiddenRoles = "forbidden-roles"
+
+	YES_FLAG = "yes"
+
+	ActionKillAll = "killall"
+)
+
+var (
+	ErrorNotConfirmed = errors.New("Confirmation did not match - aborted")
+)
+
+// What a destructive action affects within the cluster
+type BlastRadius struct {
+	Env    string
+	Action string
+	ID     string
+	// Ids of the applications affected
+	Apps      []string
+	Instances int
+	Tasks     int
+	// Ids of applications outside of those affected which depend on them
+	Dependents []string
+}
+
+func addConfirmFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP(YES_FLAG, "y", false, "Skip the confirmation prompt within protected environments")
+}
+
+// Returns true if the environment {env} has been marked as protected
+func isProtected(env string) bool {
+	return strings.ToLower(envFeature(env, FeatureProtected)) == "true"
+}
+
+func envFeature(env, feature string) string {
+	if configFile == nil {
+		return ""
+	}
+	ce, err := configFile.GetEnvironment(env)
+	if err != nil || ce.Marathon == nil {
+		return ""
+	}
+	return strings.TrimSpace(ce.Marathon.Features[feature])
+}
+
+// Returns an error if the current role may not perform destructive actions within {env}
+func checkRole(env string) error {
+	role := configFile.CurrentRole()
+	if role == "" {
+		return nil
+	}
+	for _, r := range strings.Split(envFeature(env, FeatureForbiddenRoles), ",") {
+		if strings.EqualFold(strings.TrimSpace(r), role) {
+			return fmt.Errorf("The role '%s' is not permitted to perform destructive actions within '%s'", role, env)
+		}
+	}
+	return nil
+}
+
+// Guards the destructive {action} on the app or group (if {group} is true) {id} when the current environment
+// is protected.  The blast radius is shown and the user must type {id} to continue unless --yes was specified.
+// Exits if the action is forbidden for the current role or is not confirmed
+func confirmDestructive(cmd *cobra.Command, action, id string, group bool) {
+	env := viper.GetString(ENV_NAME)
+	if !isProtected(env) {
+		return
+	}
+	if err := checkRole(env); err != nil {
+		exitWithError(err)
+	}
+
+	apps, err := client(cmd).ListApplications()
+	if err != nil {
+		exitWithError(err)
+	}
+	br := blastRadius(apps.Apps, id, group)
+	br.Env, br.Action = env, action
+	cli.Output(templateFor(T_BLAST_RADIUS, br), nil)
+
+	if yes, _ := cmd.Flags().GetBool(YES_FLAG); yes {
+		return
+	}
+
+	fmt.Printf("\n'%s' is a protected environment.  Type '%s' to confirm: ", env, id)
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if utils.TrimRootPath(strings.TrimSpace(response)) != utils.TrimRootPath(id) {
+		exitWithError(ErrorNotConfirmed)
+	}
+}
+
+// Calculates the blast radius of an action on {id}.  If {group} is true every application beneath {id}
+// is affected
+func blastRadius(apps []marathon.Application, id string, group bool) *BlastRadius {
+	br := &BlastRadius{ID: id, Apps: []string{}, Dependents: []string{}}
+	target := utils.TrimRootPath(id)
+
+	affected := func(appID string) bool {
+		a := utils.TrimRootPath(appID)
+		return a == target || (group && strings.HasPrefix(a, target+"/"))
+	}
+	covers := func(dep string) bool {
+		return dep == target || strings.HasPrefix(dep, target+"/") || strings.HasPrefix(target, dep+"/")
+	}
+
+	for i := range apps {
+		app := &apps[i]
+		if affected(app.ID) {
+			br.Apps = append(br.Apps, app.ID)
+			br.Instances += app.Instances
+			br.Tasks += app.TasksRunning
+			continue
+		}
+		for _, dep := range resolveDependencies(app.ID, app.Dependencies, []string{}) {
+			if covers(dep) {
+				br.Dependents = append(br.Dependents, app.ID)
+				break
+			}
+		}
+	}
+	sort.Strings(br.Apps)
+	sort.Strings(br.Dependents)
+	return br
+}
@@ -0,0 +1,30 @@
+package marathon
+
+import (
+	"testing"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlastRadius(t *testing.T) {
+	apps := []marathon.Application{
+		{ID: "/product/api", Instances: 3, TasksRunning: 3},
+		{ID: "/product/worker", Instances: 2, TasksRunning: 1},
+		{ID: "/product/web", Instances: 1, TasksRunning: 1, Dependencies: []string{"api"}},
+		{ID: "/reporting/etl", Instances: 1, Dependencies: []string{"/product"}},
+		{ID: "/infra/db", Instances: 1},
+	}
+
+	br := blastRadius(apps, "/product/api", false)
+	assert.Equal(t, []string{"/product/api"}, br.Apps)
+	assert.Equal(t, 3, br.Instances)
+	assert.Equal(t, 3, br.Tasks)
+	assert.Equal(t, []string{"/product/web", "/reporting/etl"}, br.Dependents)
+
+	br = blastRadius(apps, "/product", true)
+	assert.Equal(t, []string{"/product/api", "/product/web", "/product/worker"}, br.Apps)
+	assert.Equal(t, 6, br.Instances)
+	assert.Equal(t, 5, br.Tasks)
+	assert.Equal(t, []string{"/reporting/etl"}, br.Dependents)
+}
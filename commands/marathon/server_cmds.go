@@ -22,6 +22,16 @@ var serverInfoCmd = &cobra.Command{
 	},
 }
 
+var serverCapabilitiesCmd = &cobra.Command{
+	Use:     "capabilities",
+	Aliases: []string{"features"},
+	Short:   "List the features supported by the Marathon server version",
+	Run: func(cmd *cobra.Command, args []string) {
+		v, e := client(cmd).Capabilities()
+		cli.Output(templateFor(T_CAPABILITIES, v), e)
+	},
+}
+
 var serverLeaderCmd = &cobra.Command{
 	Use:   "leader",
 	Short: "Marathon leader management",
@@ -56,5 +66,5 @@ var serverLeaderAbdicateCmd = &cobra.Command{
 
 func init() {
 	serverLeaderCmd.AddCommand(serverLeaderGetCmd, serverLeaderAbdicateCmd)
-	serverCmd.AddCommand(serverInfoCmd, serverLeaderCmd, serverPingCmd)
+	serverCmd.AddCommand(serverInfoCmd, serverCapabilitiesCmd, serverLeaderCmd, serverPingCmd)
 }
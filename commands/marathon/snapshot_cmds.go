@@ -0,0 +1,198 @@
+package marathon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/cli"
+	"github.com/ContainX/depcon/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	ENCODING_FLAG = "encoding"
+)
+
+// A group or application written to a snapshot
+type SnapshotEntry struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	File string `json:"file"`
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore the definitions of every group and app within the cluster (for DR or cloning)",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save [dir]",
+	Short: "Writes every top level group and app in the cluster to [dir] as clean descriptors",
+	Long: `Walks all groups within the cluster and writes each top level group (including its sub groups and apps)
+and each app at the root as a separate descriptor within [dir].  Runtime fields such as versions, tasks
+and deployments are removed so the descriptors can be restored or deployed directly.
+
+    eg. depcon -e prod mar snapshot save ./prod-snapshot --encoding yaml`,
+	Run: saveSnapshot,
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [dir]",
+	Short: "Deploys a snapshot within [dir] in dependency order",
+	Long: `Reads every descriptor within [dir] (as written by 'snapshot save') and deploys them in dependency order.
+Use --to to restore into a different environment and --prefix to relocate everything beneath a new
+root group, for example to clone production into a staging cluster.
+
+    eg. depcon mar snapshot restore ./prod-snapshot --to staging --prefix /prod-clone`,
+	Run: restoreSnapshot,
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotSaveCmd, snapshotRestoreCmd)
+
+	snapshotSaveCmd.Flags().String(ENCODING_FLAG, "json", "The descriptor encoding to write [json | yaml]")
+
+	snapshotRestoreCmd.Flags().String(TO_ENV_FLAG, "", "Restore into this environment (default: the current environment)")
+	snapshotRestoreCmd.Flags().String(PREFIX_FLAG, "", "Relocate all groups and apps beneath this root group (eg. /dr)")
+	snapshotRestoreCmd.Flags().Int(PARALLEL_FLAG, 4, "Max number of independent descriptors to deploy at once")
+	snapshotRestoreCmd.Flags().BoolP(WAIT_FLAG, "w", false, "Wait for each descriptor to be deployed")
+	snapshotRestoreCmd.Flags().BoolP(FORCE_FLAG, "f", false, "Force deployment (updates existing groups and apps)")
+	snapshotRestoreCmd.Flags().Bool(DRYRUN_FLAG, false, "Show the deployment order - don't actually deploy")
+	snapshotRestoreCmd.Flags().DurationP(TIMEOUT_FLAG, "t", marathon.DefaultTimeout, "Max duration to wait for dependencies to become healthy (ex. 90s | 2m)")
+}
+
+func saveSnapshot(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+
+	enc, _ := cmd.Flags().GetString(ENCODING_FLAG)
+	ext, err := snapshotExt(enc)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	root, err := client(cmd).ListGroups()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	entries, err := writeSnapshot(root, args[0], ext)
+	cli.Output(templateFor(T_SNAPSHOT, entries), err)
+}
+
+// Writes each top level group and root app within {root} to {dir} as a descriptor with the extension {ext}
+func writeSnapshot(root *marathon.Groups, dir, ext string) ([]*SnapshotEntry, error) {
+	entries := []*SnapshotEntry{}
+	for _, g := range root.Groups {
+		if len(g.FlattenApps()) == 0 || g.GroupID == marathon.LockGroup {
+			continue
+		}
+		e := &SnapshotEntry{ID: g.GroupID, Kind: KindGroup, File: snapshotFile(dir, g.GroupID, ext)}
+		if err := writeDescriptor(e.File, g.Descriptor()); err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+	for _, app := range root.Apps {
+		e := &SnapshotEntry{ID: app.ID, Kind: KindApp, File: snapshotFile(dir, app.ID, ext)}
+		if err := writeDescriptor(e.File, app.Descriptor()); err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func snapshotFile(dir, id, ext string) string {
+	return filepath.Join(dir, filepath.FromSlash(utils.TrimRootPath(id))+ext)
+}
+
+func snapshotExt(enc string) (string, error) {
+	switch enc {
+	case "json":
+		return ".json", nil
+	case "yaml", "yml":
+		return ".yaml", nil
+	}
+	return "", fmt.Errorf("Invalid --%s '%s', must be [json | yaml]", ENCODING_FLAG, enc)
+}
+
+func restoreSnapshot(cmd *cobra.Command, args []string) {
+	if cli.EvalPrintUsage(Usage(cmd), args, 1) {
+		return
+	}
+
+	c, env := client(cmd), viper.GetString(ENV_NAME)
+	if to, _ := cmd.Flags().GetString(TO_ENV_FLAG); to != "" {
+		ec, err := clientForEnv(cmd, to)
+		if err != nil {
+			exitWithError(err)
+		}
+		c, env = ec, to
+	}
+
+	prefix, _ := cmd.Flags().GetString(PREFIX_FLAG)
+	descriptors, err := readSnapshot(args[0], prefix)
+	if err != nil {
+		exitWithError(err)
+	}
+	for _, d := range descriptors {
+		metadata := deployMetadata(env, d.Filename)
+		if d.IsApplication() {
+			d.App.StampMetadata(metadata)
+		} else {
+			d.Group.StampMetadata(metadata)
+		}
+	}
+
+	s := &deploySettings{}
+	s.force, _ = cmd.Flags().GetBool(FORCE_FLAG)
+	s.wait, _ = cmd.Flags().GetBool(WAIT_FLAG)
+	s.dryrun, _ = cmd.Flags().GetBool(DRYRUN_FLAG)
+	s.parallel, _ = cmd.Flags().GetInt(PARALLEL_FLAG)
+	s.timeout, _ = cmd.Flags().GetDuration(TIMEOUT_FLAG)
+
+	results, err := s.deploy(c, descriptors, false)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	cli.Output(templateFor(T_DEPLOY_SUMMARY, results), nil)
+	for _, r := range results {
+		if r.Status != DeploySuccess && r.Status != DeployPlanned {
+			os.Exit(1)
+		}
+	}
+}
+
+// Reads every descriptor within the snapshot {dir} relocating them beneath {prefix} if specified
+func readSnapshot(dir, prefix string) ([]*Descriptor, error) {
+	files, err := findDescriptors(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors := []*Descriptor{}
+	for _, f := range files {
+		d, err := readDescriptor(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", f, err.Error())
+		}
+		if prefix != "" {
+			if d.IsApplication() {
+				d.App.Rebase(prefix)
+			} else {
+				d.Group.Rebase(prefix)
+			}
+		}
+		descriptors = append(descriptors, d)
+	}
+	return descriptors, nil
+}
@@ -0,0 +1,52 @@
+package marathon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotSaveAndRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	root := &marathon.Groups{
+		GroupID: "/",
+		Groups: []*marathon.Group{
+			{GroupID: "/product", Version: "2017-06-01T10:00:00.000Z", Apps: []*marathon.Application{
+				{ID: "/product/api", Instances: 2, TasksRunning: 2, Dependencies: []string{"/db"}},
+			}},
+			{GroupID: "/empty"},
+		},
+		Apps: []*marathon.Application{{ID: "/db", Instances: 1, Version: "2017-06-01T10:00:00.000Z"}},
+	}
+
+	entries, err := writeSnapshot(root, dir, ".yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, filepath.Join(dir, "product.yaml"), entries[0].File)
+	assert.Equal(t, filepath.Join(dir, "db.yaml"), entries[1].File)
+
+	descriptors, err := readSnapshot(dir, "/clone")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(descriptors))
+
+	assert.True(t, descriptors[0].IsApplication())
+	assert.Equal(t, "/clone/db", descriptors[0].App.ID)
+	assert.Equal(t, "", descriptors[0].App.Version)
+
+	assert.Equal(t, "/clone/product", descriptors[1].Group.GroupID)
+	assert.Equal(t, "", descriptors[1].Group.Version)
+	assert.Equal(t, "/clone/product/api", descriptors[1].Group.Apps[0].ID)
+	assert.Equal(t, []string{"/clone/db"}, descriptors[1].Group.Apps[0].Dependencies)
+	assert.Equal(t, 0, descriptors[1].Group.Apps[0].TasksRunning)
+
+	nodes, err := buildDependencyGraph(descriptors)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"clone/db", "clone/product"}, dependencyOrder(nodes))
+}
@@ -0,0 +1,237 @@
+package marathon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/ContainX/depcon/pkg/httpclient"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// Descriptor argument which reads the descriptor from stdin
+	STDIN_SOURCE = "-"
+
+	SOURCE_TOKEN_FLAG string = "source-token"
+	SOURCE_USER_FLAG  string = "source-user"
+	INPUT_FORMAT_FLAG string = "input-format"
+)
+
+var (
+	ErrorMultipleStdin    = errors.New("stdin ('-') may only be specified once")
+	ErrorPlainCredentials = errors.New("refusing to send --source-token or --source-user over plain http, use an https url")
+
+	// Read when a descriptor is specified as '-'
+	sourceStdin io.Reader = os.Stdin
+
+	sourcesMu  sync.Mutex
+	sourcesDir string
+	// original names (stdin or url) of the fetched sources keyed by their local file
+	sourceNames = map[string]string{}
+)
+
+// Returns true if {src} is an http(s) url
+func isRemoteSource(src string) bool {
+	return strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")
+}
+
+// Replaces any stdin ('-') or http(s) sources within {files} with local copies so they can be handled like
+// any other descriptor file.  The --input-format flag of {cmd} declares the format of sources whose format
+// cannot be determined from their name
+func resolveSources(cmd *cobra.Command, files []string) ([]string, error) {
+	format, _ := cmd.Flags().GetString(INPUT_FORMAT_FLAG)
+	resolved := []string{}
+	stdin := false
+	for _, f := range files {
+		if f == STDIN_SOURCE {
+			if stdin {
+				return nil, ErrorMultipleStdin
+			}
+			stdin = true
+		}
+		local, err := fetchSource(f, format)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, local)
+	}
+	return resolved, nil
+}
+
+// Returns a local file holding the content of {src}.  Local files are returned as is.  Stdin ('-') and
+// http(s) sources are written to a temporary directory which is removed when depcon exits.  Remote
+// sources may pin their content with a checksum fragment (eg. https://host/app.yaml#sha256=<hex>)
+//
+// {src}    - the filename, '-' or url
+// {format} - json or yaml, if empty the format is determined by the url extension or the content
+func fetchSource(src, format string) (string, error) {
+	if src != STDIN_SOURCE && !isRemoteSource(src) {
+		return src, nil
+	}
+
+	var data []byte
+	var name, checksum string
+	var err error
+	if src == STDIN_SOURCE {
+		name = "stdin"
+		if data, err = ioutil.ReadAll(sourceStdin); err != nil {
+			return "", err
+		}
+	} else {
+		u, err := url.Parse(src)
+		if err != nil {
+			return "", err
+		}
+		if checksum, err = sourceChecksum(u.Fragment); err != nil {
+			return "", fmt.Errorf("%s: %s", src, err.Error())
+		}
+		u.Fragment = ""
+		if data, err = fetchRemote(u.String()); err != nil {
+			return "", fmt.Errorf("%s: %s", src, err.Error())
+		}
+		name = path.Base(u.Path)
+	}
+
+	if checksum != "" {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); actual != checksum {
+			return "", fmt.Errorf("%s: checksum mismatch, expected sha256 %s but was %s", src, checksum, actual)
+		}
+	}
+
+	if !isBundleArchive(name) {
+		et, err := sourceEncoderType(name, format, string(data))
+		if err != nil {
+			return "", fmt.Errorf("%s: %s", src, err.Error())
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + et.Extension()
+	}
+	return writeSource(src, name, data)
+}
+
+// Returns the encoder type of a source named {name} from the --input-format {format}, the name's extension or
+// lastly by sniffing the {data}
+func sourceEncoderType(name, format, data string) (encoding.EncoderType, error) {
+	if format != "" {
+		return encoding.EncoderTypeFromFormat(format)
+	}
+	if et, err := encoding.EncoderTypeFromExt(name); err == nil {
+		return et, nil
+	}
+	return encoding.SniffEncoderType(data), nil
+}
+
+// Returns the expected sha256 (hex) declared by the url {fragment} (eg. sha256=<hex>)
+func sourceChecksum(fragment string) (string, error) {
+	if fragment == "" {
+		return "", nil
+	}
+	kv := strings.SplitN(fragment, "=", 2)
+	if len(kv) != 2 || strings.ToLower(kv[0]) != "sha256" || kv[1] == "" {
+		return "", fmt.Errorf("unsupported checksum '%s', expected sha256=<hex>", fragment)
+	}
+	return strings.ToLower(kv[1]), nil
+}
+
+// Downloads {rawurl} authenticating with the --source-token (bearer) or --source-user (user:password).
+// Credentials are only sent over https
+func fetchRemote(rawurl string) ([]byte, error) {
+	token, user := viper.GetString(SOURCE_TOKEN_FLAG), viper.GetString(SOURCE_USER_FLAG)
+	if (token != "" || user != "") && !strings.HasPrefix(rawurl, "https://") {
+		return nil, ErrorPlainCredentials
+	}
+
+	config := httpclient.NewDefaultConfig()
+	config.TLSInsecureSkipVerify = viper.GetBool(INSECURE_FLAG)
+
+	req, err := http.NewRequest("GET", rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if user != "" {
+		up := strings.SplitN(user, ":", 2)
+		if len(up) == 1 {
+			up = append(up, "")
+		}
+		req.SetBasicAuth(up[0], up[1])
+	}
+
+	resp, err := httpclient.NewHttpClient(config).Unwrap().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, httpclient.ErrorNotAuthenticated
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, httpclient.ErrorNotAuthorized
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Writes the fetched {data} of {src} to {name} within the sources directory
+func writeSource(src, name string, data []byte) (string, error) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+
+	if sourcesDir == "" {
+		dir, err := ioutil.TempDir("", "depcon-sources")
+		if err != nil {
+			return "", err
+		}
+		sourcesDir = dir
+		onExit(removeSources)
+	}
+
+	// each source gets its own directory so sources with the same name do not collide
+	dir := filepath.Join(sourcesDir, fmt.Sprintf("%d", len(sourceNames)+1))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	f := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(f, data, 0600); err != nil {
+		return "", err
+	}
+	sourceNames[f] = src
+	return f, nil
+}
+
+// Returns the name {file} was fetched from (stdin or url) or {file} if it is a local file
+func sourceName(file string) string {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if src, ok := sourceNames[file]; ok {
+		return src
+	}
+	return file
+}
+
+// Removes the local copies of fetched sources
+func removeSources() {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if sourcesDir != "" {
+		os.RemoveAll(sourcesDir)
+	}
+	sourcesDir = ""
+	sourceNames = map[string]string{}
+}
@@ -0,0 +1,104 @@
+package marathon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const remoteDescriptor = "id: /product/api\ninstances: 2\n"
+
+func sourceServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(remoteDescriptor))
+	}))
+}
+
+func TestFetchRemoteSource(t *testing.T) {
+	server := sourceServer()
+	defer server.Close()
+	defer removeSources()
+
+	viper.Set(INSECURE_FLAG, true)
+	defer viper.Set(INSECURE_FLAG, false)
+	viper.Set(SOURCE_TOKEN_FLAG, "secret")
+	defer viper.Set(SOURCE_TOKEN_FLAG, "")
+
+	sum := sha256.Sum256([]byte(remoteDescriptor))
+	src := server.URL + "/descriptors/api#sha256=" + hex.EncodeToString(sum[:])
+	local, err := fetchSource(src, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "api.yaml", filepath.Base(local))
+	assert.Equal(t, src, sourceName(local))
+
+	data, err := ioutil.ReadFile(local)
+	assert.NoError(t, err)
+	assert.Equal(t, remoteDescriptor, string(data))
+
+	_, err = fetchSource(server.URL+"/api.yaml#sha256=0123", "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+
+	_, err = fetchSource(server.URL+"/api.yaml#md5=0123", "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported checksum")
+}
+
+func TestFetchRemoteSourceNotAuthenticated(t *testing.T) {
+	server := sourceServer()
+	defer server.Close()
+
+	viper.Set(INSECURE_FLAG, true)
+	defer viper.Set(INSECURE_FLAG, false)
+	_, err := fetchSource(server.URL+"/api.yaml", "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Not Authenticated")
+}
+
+func TestFetchRemoteSourcePlainCredentials(t *testing.T) {
+	viper.Set(SOURCE_USER_FLAG, "deployer:secret")
+	defer viper.Set(SOURCE_USER_FLAG, "")
+
+	_, err := fetchSource("http://artifacts/product/api.yaml", "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrorPlainCredentials.Error())
+}
+
+func TestFetchStdinSource(t *testing.T) {
+	defer removeSources()
+	defer func(r io.Reader) { sourceStdin = r }(sourceStdin)
+
+	sourceStdin = strings.NewReader(`{"id": "/product/api"}`)
+	local, err := fetchSource(STDIN_SOURCE, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "stdin.json", filepath.Base(local))
+	assert.Equal(t, STDIN_SOURCE, sourceName(local))
+
+	sourceStdin = strings.NewReader(remoteDescriptor)
+	local, err = fetchSource(STDIN_SOURCE, "yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "stdin.yaml", filepath.Base(local))
+
+	local, err = fetchSource("apps/api.yaml", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "apps/api.yaml", local)
+}
+
+func TestExpandDescriptorArgsKeepsSources(t *testing.T) {
+	files, err := expandDescriptorArgs([]string{STDIN_SOURCE, "https://artifacts/api.yaml?version=1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{STDIN_SOURCE, "https://artifacts/api.yaml?version=1"}, files)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/ContainX/depcon/pkg/cli"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var taskCmd = &cobra.Command{
@@ -58,7 +59,9 @@ func init() {
 	// Task Kill Flags
 	appTaskKillallCmd.Flags().String(HOST_FLAG, "", "Kill only those tasks running on host [host]. Default: none.")
 	appTaskKillallCmd.Flags().Bool(SCALE_FLAG, false, "Scale the app down (i.e. decrement its instances setting by the number of tasks killed)")
+	addConfirmFlags(appTaskKillallCmd)
 	appTaskKillCmd.Flags().Bool(SCALE_FLAG, false, "Scale the app down (i.e. decrement its instances setting by the number of tasks killed)")
+	addConfirmFlags(appTaskKillCmd)
 }
 
 func appTasks(cmd *cobra.Command, args []string) {
@@ -90,6 +93,7 @@ func appKillAllTasks(cmd *cobra.Command, args []string) {
 	host, _ := cmd.Flags().GetString(HOST_FLAG)
 	scale, _ := cmd.Flags().GetBool(SCALE_FLAG)
 
+	confirmDestructive(cmd, ActionKillAll, args[0], false)
 	v, e := client(cmd).KillAppTasks(args[0], host, scale)
 	cli.Output(templateFor(T_TASKS, v), e)
 }
@@ -99,6 +103,25 @@ func appKillTask(cmd *cobra.Command, args []string) {
 		return
 	}
 	scale, _ := cmd.Flags().GetBool(SCALE_FLAG)
+	if scale && isProtected(viper.GetString(ENV_NAME)) {
+		// scaling down removes an instance of the task's app
+		confirmDestructive(cmd, ActionScale, taskAppID(cmd, args[0]), false)
+	}
 	v, e := client(cmd).KillAppTask(args[0], scale)
 	cli.Output(templateFor(T_TASK, v), e)
 }
+
+// Returns the id of the app running the task {taskID}
+func taskAppID(cmd *cobra.Command, taskID string) string {
+	tasks, err := client(cmd).ListTasks()
+	if err != nil {
+		exitWithError(err)
+	}
+	for _, t := range tasks {
+		if t.ID == taskID {
+			return t.AppID
+		}
+	}
+	exitWithError(fmt.Errorf("Task '%s' could not be found", taskID))
+	return ""
+}
@@ -8,6 +8,7 @@ import (
 
 	"fmt"
 	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/ContainX/depcon/pkg/jsonschema"
 	"github.com/spf13/viper"
 	"path/filepath"
 	"strings"
@@ -41,7 +42,7 @@ func (ctx *TemplateContext) TransformWithEnv(writer io.Writer, descriptor, rootD
 		return err
 	} else {
 		var e error
-		t = template.New(descriptor).Funcs(Funcs)
+		t = template.New(descriptor).Funcs(funcsForEnv(env))
 		t, e = t.Parse(string(b))
 		if e != nil {
 			return e
@@ -119,9 +120,18 @@ func TemplateExists(filename string) bool {
 }
 
 func LoadTemplateContext(filename string) (*TemplateContext, error) {
+	if isRemoteSource(filename) {
+		local, err := fetchSource(filename, "json")
+		if err != nil {
+			return nil, err
+		}
+		filename = local
+	}
+
 	// Return empty context if non-exists
 	if !TemplateExists(filename) {
-		fmt.Println("Ignoring context file (not found) - template-context.json")
+		// written to stderr so descriptors printed to stdout (eg. app export, --dry-run) remain valid
+		fmt.Fprintln(os.Stderr, "Ignoring context file (not found) - template-context.json")
 		return &TemplateContext{Environments: make(map[string]*TemplateEnvironment)}, nil
 	}
 
@@ -132,13 +142,59 @@ func LoadTemplateContext(filename string) (*TemplateContext, error) {
 
 	encoder, err := encoding.NewEncoder(encoding.JSON)
 	if err != nil {
-		return nil, fmt.Errorf(ContextErrFmt, filename, err.Error())
+		return nil, fmt.Errorf(ContextErrFmt, sourceName(filename), err.Error())
 	}
 
 	result := &TemplateContext{Environments: make(map[string]*TemplateEnvironment)}
 
 	if err := encoder.UnMarshal(ctx, result); err != nil {
-		return nil, fmt.Errorf(ContextErrFmt, filename, err.Error())
+		return nil, fmt.Errorf(ContextErrFmt, sourceName(filename), err.Error())
 	}
 	return result, nil
 }
+
+// Sets the {values} of {app} within the environment {env}.  Existing values for the app are kept unless
+// they are replaced by one of the {values}
+func (ctx *TemplateContext) SetAppValues(env, app string, values map[string]interface{}) {
+	if ctx.Environments == nil {
+		ctx.Environments = make(map[string]*TemplateEnvironment)
+	}
+	env = strings.ToLower(env)
+	te, ok := ctx.Environments[env]
+	if !ok {
+		te = &TemplateEnvironment{}
+		ctx.Environments[env] = te
+	}
+	if te.Apps == nil {
+		te.Apps = make(map[string]map[string]interface{})
+	}
+	if te.Apps[app] == nil {
+		te.Apps[app] = make(map[string]interface{})
+	}
+	for k, v := range values {
+		te.Apps[app][k] = v
+	}
+}
+
+// Returns the JSON schema of template context files
+func TemplateContextSchema() *jsonschema.Schema {
+	r := &jsonschema.Reflector{Descriptions: map[string]string{
+		"TemplateContext":              "Values available to descriptor templates per environment",
+		"TemplateContext.environments": "Values keyed by environment name.  Values within the \"-\" environment are the defaults of every environment",
+		"TemplateEnvironment.apps":     "Values keyed by app and then value name (eg. {{ .myapp.instances }})",
+	}}
+	return r.Reflect("Depcon Template Context", &TemplateContext{})
+}
+
+// Writes the template context to {filename} as JSON
+func (ctx *TemplateContext) Save(filename string) error {
+	encoder, err := encoding.NewEncoder(encoding.JSON)
+	if err != nil {
+		return err
+	}
+	data, err := encoder.MarshalIndent(ctx)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, []byte(data), 0644)
+}
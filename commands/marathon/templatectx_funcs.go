@@ -32,9 +32,24 @@ func FuncMap() template.FuncMap {
 		"env":        func(s string) string { return os.Getenv(s) },
 		"expandenv":  func(s string) string { return os.ExpandEnv(s) },
 		"N":          N,
+		"secret":     secretRef,
+		"secretDef":  secretDef,
 	}
 }
 
+// Returns the template functions with isEnv and isNotEnv bound to {env} instead of the current
+// environment so descriptors can be rendered for several environments at once
+func funcsForEnv(env string) template.FuncMap {
+	funcs := FuncMap()
+	funcs["isEnv"] = func(value string) bool {
+		return len(value) > 0 && strings.ToLower(env) == strings.ToLower(value)
+	}
+	funcs["isNotEnv"] = func(value string) bool {
+		return !(len(value) > 0 && strings.ToLower(env) == strings.ToLower(value))
+	}
+	return funcs
+}
+
 func isEnv(value string) bool {
 	if len(value) > 0 {
 		current := strings.ToLower(viper.GetString(ENV_NAME))
@@ -43,6 +58,16 @@ func isEnv(value string) bool {
 	return false
 }
 
+// Renders an env value referencing the secret {name}.  eg. "DB_PASSWORD": {{ secret "db-password" }}
+func secretRef(name string) string {
+	return fmt.Sprintf(`{"secret": %s}`, strconv.Quote(name))
+}
+
+// Renders a secrets entry declaring {name} sourced from {source}.  eg. "secrets": { {{ secretDef "db-password" "/prod/db/password" }} }
+func secretDef(name, source string) string {
+	return fmt.Sprintf(`%s: {"source": %s}`, strconv.Quote(name), strconv.Quote(source))
+}
+
 func isNotEnv(value string) bool {
 	return !isEnv(value)
 }
@@ -1,8 +1,15 @@
 package marathon
 
 import (
+	"bytes"
+	"encoding/json"
+	"github.com/ContainX/depcon/marathon"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"text/template"
 )
 
 func TestMergeFunctionality(t *testing.T) {
@@ -16,3 +23,30 @@ func TestMergeFunctionality(t *testing.T) {
 	assert.Equal(t, float64(300), m["appa"]["mem"])
 
 }
+
+func TestSecretTemplateFuncs(t *testing.T) {
+	tmpl := `{"env": {"DB_PASSWORD": {{ secret "db-password" }}}, "secrets": { {{ secretDef "db-password" "/prod/db/password" }} }}`
+	var buf bytes.Buffer
+	err := template.Must(template.New("secrets").Funcs(Funcs).Parse(tmpl)).Execute(&buf, nil)
+	assert.NoError(t, err)
+
+	app := new(marathon.Application)
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), app))
+	assert.Equal(t, marathon.EnvSecret("db-password"), app.Env["DB_PASSWORD"])
+	assert.Equal(t, "/prod/db/password", app.Secrets["db-password"].Source)
+}
+
+func TestTransformWithEnvBindsIsEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depcon")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	descriptor := filepath.Join(dir, "app.json")
+	ioutil.WriteFile(descriptor, []byte(`{{ if isEnv "prod" }}prod{{ else }}other{{ end }}`), 0644)
+
+	ctx := &TemplateContext{Environments: map[string]*TemplateEnvironment{}}
+	for env, expected := range map[string]string{"prod": "prod", "qa": "other"} {
+		var buf bytes.Buffer
+		assert.NoError(t, ctx.TransformWithEnv(&buf, descriptor, dir, env))
+		assert.Equal(t, expected, buf.String())
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"github.com/ContainX/depcon/pkg/cli"
 	"github.com/ContainX/depcon/utils"
 	"io"
+	"strings"
 	"text/template"
+	"time"
 )
 
 const (
@@ -34,8 +36,18 @@ const (
 {{ "Environment:" }}
 {{ range $key, $value := .Env }}		{{ $key | pad }} {{ $value }}
 {{end}}
+{{- if .Secrets }}
+{{ "Secrets:" }}
+{{ range $key, $value := .Secrets }}		{{ $key | pad }} {{ $value.Source }}
+{{end}}
+{{- end }}
+{{- if .MetadataLabels }}
+{{ "Deployment:" }}
+{{ range $key, $value := .MetadataLabels }}		{{ $key | metadataName | pad }} {{ $value }}
+{{end}}
+{{- end }}
 {{ "Labels:" }}
-{{ range $key, $value := .Labels }}		{{ $key | pad }} {{ $value }}
+{{ range $key, $value := .UserLabels }}		{{ $key | pad }} {{ $value }}
 {{end}}
 `
 
@@ -98,9 +110,127 @@ const (
 {{ "ZK:" }}	{{ .ZookeeperConfig.Zk }}
 {{ "Timeout:" }}	{{ .ZookeeperConfig.ZkTimeout | valString }}
 `
+	T_CAPABILITIES = `
+{{ "Version:" }}	{{ .Version }}
+
+{{ "CAPABILITY" }}	{{ "MIN_VERSION" }}	{{ "SUPPORTED" }}
+{{ range .List }}{{ .Name }}	{{ .MinVersion }}	{{ .Supported | boolToYesNo }}
+{{end}}`
+
 	T_QUEUED_TASKS = `
 {{ "APP_ID" }}	{{ "VERSION" }}	{{ "OVERDUE" }}
-{{ range .Queue }}{{ .App.ID }}	{{ .App.Version }}	{{ .Delay.overdue | valString }}
+{{ range .Queue }}{{ .App.ID }}	{{ .App.Version }}	{{ .Delay.Overdue | valString }}
+{{end}}`
+
+	T_QUEUE_DIAGNOSIS = `
+{{ "App:" }}	{{ .AppID }}
+{{ "Queued:" }}	{{ .Queued | boolToYesNo }}
+{{- if .Queued }}
+{{ "Tasks Waiting:" }}	{{ .Count | intToString }}
+{{ "Queued Since:" }}	{{ .Since | fdate }}
+{{ "Launch Delay:" }}	{{ .Delay.TimeLeftSeconds | intToString }}s (overdue: {{ .Delay.Overdue | boolToYesNo }}){{ if .DelayReset }} - delay has been reset{{ end }}
+{{ "Offers Processed:" }}	{{ .ProcessedOffers | intToString }}
+{{ "Offers Unused:" }}	{{ .UnusedOffers | intToString }}
+{{ "Last Unused Offer:" }}	{{ .LastUnusedOffer | fdate }}
+
+{{ "DECLINE_REASON" }}	{{ "DECLINED" }}	{{ "PROCESSED" }}	{{ "HINT" }}
+{{ range .Reasons }}{{ .Reason }}	{{ .Declined | intToString }}	{{ .Processed | intToString }}	{{ .Hint }}
+{{end}}
+{{- if .LastUnusedOffers }}
+{{ "OFFER_HOST" }}	{{ "TIMESTAMP" }}	{{ "REASONS" }}
+{{ range .LastUnusedOffers }}{{ .Offer | offerHost }}	{{ .Timestamp | fdate }}	{{ .Reason | strConcat }}
+{{end}}
+{{- end }}
+{{- end }}
+{{ if .Readiness }}
+{{ "TASK_ID" }}	{{ "READINESS_CHECK" }}	{{ "READY" }}
+{{ range .Readiness }}{{ .TaskID }}	{{ .Name }}	{{ .Ready | boolToYesNo }}
+{{end}}
+{{- end }}
+{{- with .LastTaskFailure }}
+{{ "Last Task Failure:" }}	{{ "Task" | pad }} {{ .TaskID }}
+	{{ "Host" | pad }} {{ .Host }}
+	{{ "State" | pad }} {{ .State }}
+	{{ "Time" | pad }} {{ .Timestamp | fdate }}
+	{{ "Message" | pad }} {{ .Message }}
+{{- end }}
+`
+
+	T_SUBSCRIPTIONS = `
+{{ "CALLBACK_URL" }}
+{{ range .CallbackURLs }}{{ . }}
+{{end}}`
+
+	T_SUBSCRIPTION_CHANGE = `
+{{ "CALLBACK_URL" }}	{{ "EVENT" }}	{{ "CLIENT_IP" }}
+{{ .CallbackURL }}	{{ .EventType }}	{{ .ClientIP }}
+`
+
+	T_SUBSCRIPTION_SYNC = `
+{{ "CALLBACK_URL" }}	{{ "ACTION" }}
+{{ range . }}{{ .CallbackURL }}	{{ .Action }}
+{{end}}`
+
+	T_APPLY_RESULTS = `
+{{ "ID" }}	{{ "KIND" }}	{{ "ACTION" }}	{{ "CHANGES" }}	{{ "ERROR" }}
+{{ range . }}{{ .ID }}	{{ .Kind }}	{{ .Action }}	{{ .Changes | changePaths }}	{{ .Error }}
+{{end}}`
+
+	T_DEPLOY_SUMMARY = `
+{{ "ID" }}	{{ "KIND" }}	{{ "STATUS" }}	{{ "ELAPSED" }}	{{ "DEPENDS_ON" }}	{{ "ERROR" }}
+{{ range . }}{{ .ID }}	{{ .Kind }}	{{ .Status }}	{{ .Elapsed }}	{{ .DependsOn | strConcat }}	{{ .Error }}
+{{end}}`
+
+	T_ENV_DEPLOY_SUMMARY = `
+{{ "ENV" }}	{{ "ID" }}	{{ "STATUS" }}	{{ "ELAPSED" }}	{{ "ERROR" }}
+{{ range . }}{{ $env := .Env }}{{ if .Results }}{{ range .Results }}{{ $env }}	{{ .ID }}	{{ .Status }}	{{ .Elapsed }}	{{ .Error }}
+{{ end }}{{ else }}{{ .Env }}	{{ "-" }}	{{ .Status }}		{{ .Error }}
+{{ end }}{{ end }}`
+
+	T_SNAPSHOT = `
+{{ "ID" }}	{{ "KIND" }}	{{ "FILE" }}
+{{ range . }}{{ .ID }}	{{ .Kind }}	{{ .File }}
+{{end}}`
+
+	T_POLICY_VIOLATIONS = `
+{{ "FILE" }}	{{ "ID" }}	{{ "POLICY" }}	{{ "ACTION" }}	{{ "MESSAGE" }}
+{{ range . }}{{ .File }}	{{ .ID }}	{{ .Policy }}	{{ .Action }}	{{ .Message }}
+{{end}}`
+
+	T_LINT = `
+{{ "FILE" }}	{{ "ID" }}	{{ "RULE" }}	{{ "SEVERITY" }}	{{ "PATH" }}	{{ "MESSAGE" }}
+{{ range . }}{{ .File }}	{{ .ID }}	{{ .Rule }}	{{ .Severity }}	{{ .Path }}	{{ .Message }}
+{{end}}`
+
+	T_LINT_RULES = `
+{{ "RULE" }}	{{ "SEVERITY" }}	{{ "DESCRIPTION" }}
+{{ range . }}{{ .ID }}	{{ .Severity }}	{{ .Description }}
+{{end}}`
+
+	T_BLAST_RADIUS = `
+{{ "ENVIRONMENT:" }}	{{ .Env }} (protected)
+{{ "ACTION:" }}	{{ .Action }} {{ .ID }}
+{{ "APPS AFFECTED:" }}	{{ len .Apps }}{{ range .Apps }}
+	{{ . }}{{ end }}
+{{ "INSTANCES:" }}	{{ .Instances }}
+{{ "RUNNING TASKS:" }}	{{ .Tasks }}
+{{ "DEPENDENT APPS:" }}	{{ if .Dependents }}{{ range .Dependents }}
+	{{ . }}{{ end }}{{ else }}none{{ end }}
+`
+
+	T_LOCKS = `
+{{ "TARGET" }}	{{ "OWNER" }}	{{ "ACQUIRED" }}	{{ "EXPIRES" }}	{{ "REASON" }}
+{{ range . }}{{ .Target }}	{{ .Owner }}	{{ .Acquired | ftime }}	{{ .Expires | ftime }}{{ if .Expired }} (expired){{ end }}	{{ .Reason }}
+{{end}}`
+
+	T_FIELD_CHANGES = `
+{{ "PATH" }}	{{ "CURRENT" }}	{{ "NEW" }}
+{{ range . }}{{ .Path }}	{{ .LiveString }}	{{ .DesiredString }}
+{{end}}`
+
+	T_COMPARISON = `
+{{ "PATH" }}{{ range .Envs }}	{{ . | upper }}{{ end }}
+{{ range .Rows }}{{ .Path }}{{ range .Values }}	{{ . }}{{ end }}
 {{end}}`
 
 	T_MESSAGE = `
@@ -130,14 +260,28 @@ func (d Templated) Data() cli.FormatData {
 
 func buildFuncMap() template.FuncMap {
 	funcMap := template.FuncMap{
-		"intConcat":   utils.ConcatInts,
-		"idConcat":    utils.ConcatIdentifiers,
-		"dockerImage": dockerImageOrEmpty,
-		"hasDocker":   hasDocker,
+		"intConcat":    utils.ConcatInts,
+		"idConcat":     utils.ConcatIdentifiers,
+		"dockerImage":  dockerImageOrEmpty,
+		"hasDocker":    hasDocker,
+		"offerHost":    offerHostOrEmpty,
+		"strConcat":    utils.ConcatIdentifiers,
+		"changePaths":  changePaths,
+		"upper":        strings.ToUpper,
+		"metadataName": metadataLabelName,
+		"ftime":        formatTime,
 	}
 	return funcMap
 }
 
+func changePaths(changes []*marathon.FieldChange) string {
+	paths := make([]string, len(changes))
+	for i, c := range changes {
+		paths[i] = c.Path
+	}
+	return strings.Join(paths, ", ")
+}
+
 func hasDocker(c *marathon.Container) bool {
 	return c != nil && c.Docker != nil
 }
@@ -148,3 +292,18 @@ func dockerImageOrEmpty(c *marathon.Container) string {
 	}
 	return ""
 }
+
+func offerHostOrEmpty(o *marathon.Offer) string {
+	if o != nil {
+		return o.Hostname
+	}
+	return ""
+}
+
+func metadataLabelName(key string) string {
+	return strings.TrimPrefix(key, marathon.MetadataLabelPrefix)
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
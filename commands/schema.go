@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ContainX/depcon/commands/marathon"
+	mar "github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/ContainX/depcon/pkg/jsonschema"
+	"github.com/spf13/cobra"
+)
+
+const (
+	SchemaApp             = "app"
+	SchemaGroup           = "group"
+	SchemaPod             = "pod"
+	SchemaTemplateContext = "template-context"
+)
+
+var (
+	schemas = map[string]func() *jsonschema.Schema{
+		SchemaApp:             mar.ApplicationSchema,
+		SchemaGroup:           mar.GroupSchema,
+		SchemaPod:             mar.PodSchema,
+		SchemaTemplateContext: marathon.TemplateContextSchema,
+	}
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [app | group | pod | template-context]",
+	Short: "Outputs the JSON schema of descriptors and template context files",
+	Long: `Generates the JSON schema of app, group and pod descriptors or template context files.  The schema can be used by
+editors and YAML language servers for completion and validation.  It is the same schema descriptors are validated
+against with --strict.  Use -o yaml to output the schema as YAML.
+
+    eg. depcon schema app > app.schema.json
+
+    # .vscode/settings.json
+    "yaml.schemas": { "./app.schema.json": "apps/*.yaml" }`,
+	ValidArgs: []string{SchemaApp, SchemaGroup, SchemaPod, SchemaTemplateContext},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			return
+		}
+		schema, ok := schemas[args[0]]
+		if !ok {
+			PrintError(fmt.Errorf("Unknown schema '%s', must be [app | group | pod | template-context]", args[0]))
+		}
+
+		encoder := encoding.DefaultJSONEncoder()
+		if getFormatType() == TypeYAML {
+			encoder = encoding.DefaultYAMLEncoder()
+		}
+		data, err := encoder.MarshalIndent(schema())
+		if err != nil {
+			PrintError(err)
+		}
+		fmt.Println(data)
+	},
+}
@@ -17,6 +17,10 @@ const (
 	ActionRestart  = "restart"
 	ActionVersions = "versions"
 	PathTasks      = "tasks"
+
+	// Embed options which enrich the application response
+	EmbedAppReadiness       = "app.readiness"
+	EmbedAppLastTaskFailure = "app.lastTaskFailure"
 )
 
 var (
@@ -77,7 +81,7 @@ func (c *MarathonClient) ParseApplicationFromFile(filename string, opts *CreateO
 	if et, err := encoding.EncoderTypeFromExt(filename); err != nil {
 		return nil, err
 	} else {
-		return c.ParseApplicationFromString(file, et, opts)
+		return c.ParseApplicationFromString(file, et, withSource(opts, filename))
 	}
 }
 
@@ -105,16 +109,40 @@ func (c *MarathonClient) ParseApplicationFromString(r io.Reader, et encoding.Enc
 	}
 
 	app := new(Application)
-	err = encoder.UnMarshalStr(parsed, &app)
+	if options.Strict {
+		if err = encoding.UnMarshalStrict(et, parsed, app, options.Source); err == nil {
+			err = validateSchema(ApplicationSchema(), et, parsed, options.Source)
+		}
+	} else {
+		err = encoder.UnMarshalStr(parsed, &app)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if err := app.checkSecrets(); err != nil {
+		return nil, err
+	}
+	if options.Metadata != nil {
+		app.StampMetadata(options.Metadata)
+	}
+	if options.Verifier != nil {
+		if err := options.Verifier.VerifyApplication(app); err != nil {
+			return nil, err
+		}
+	}
 	return app, nil
 }
 
 func (c *MarathonClient) CreateApplication(app *Application, wait, force bool) (*Application, error) {
 	c.logOutput(log.Infof, "Creating Application '%s', wait: %v, force: %v", app.ID, wait, force)
 
+	if err := c.checkCapabilities(app.ID, app.RequiredCapabilities()); err != nil {
+		return nil, err
+	}
+	if err := c.checkGuardrails(app.ID, func(g *Guardrails) []string { return g.CheckApplication(app) }); err != nil {
+		return nil, err
+	}
+
 	result := new(Application)
 	resp := c.http.HttpPost(c.marathonUrl(API_APPS), app, result)
 	if resp.Error != nil {
@@ -126,6 +154,9 @@ func (c *MarathonClient) CreateApplication(app *Application, wait, force bool) (
 				return nil, ErrorAppExists
 			}
 			if resp.Status == 422 {
+				if verr := parseValidationError(resp.Content); verr != nil && len(verr.Details) > 0 {
+					return nil, verr
+				}
 				return nil, fmt.Errorf("Error occurred: %s", resp.Content)
 			}
 			return nil, fmt.Errorf("Error occurred (Status %v) Body -> %s", resp.Status, resp.Content)
@@ -147,6 +178,14 @@ func (c *MarathonClient) CreateApplication(app *Application, wait, force bool) (
 
 func (c *MarathonClient) UpdateApplication(app *Application, wait bool, force bool) (*Application, error) {
 	log.Infof("Update Application '%s', wait = %v", app.ID, wait)
+	if err := c.checkCapabilities(app.ID, app.RequiredCapabilities()); err != nil {
+		return nil, err
+	}
+	if app.Version == "" {
+		if err := c.checkGuardrails(app.ID, func(g *Guardrails) []string { return g.CheckUpdate(app) }); err != nil {
+			return nil, err
+		}
+	}
 	result := new(DeploymentID)
 	id := utils.TrimRootPath(app.ID)
 	app.ID = ""
@@ -159,6 +198,9 @@ func (c *MarathonClient) UpdateApplication(app *Application, wait bool, force bo
 	if resp.Error != nil {
 		if resp.Error == httpclient.ErrorMessage {
 			if resp.Status == 422 {
+				if verr := parseValidationError(resp.Content); verr != nil && len(verr.Details) > 0 {
+					return nil, verr
+				}
 				return nil, ErrorNoAppExists
 			}
 		}
@@ -211,6 +253,27 @@ func (c *MarathonClient) GetApplication(id string) (*Application, error) {
 	return &app.App, nil
 }
 
+// Gets an application by Id including the specified embedded resources
+// {id}     - application identifier
+// {embeds} - one or more embed options (eg. EmbedAppReadiness)
+func (c *MarathonClient) GetApplicationWithEmbed(id string, embeds ...string) (*Application, error) {
+	log.Debugf("Enter: GetApplicationWithEmbed: %s, embed: %v", id, embeds)
+	app := new(AppById)
+	url := c.marathonUrl(API_APPS, id)
+	for idx, embed := range embeds {
+		sep := "&"
+		if idx == 0 {
+			sep = "?"
+		}
+		url = fmt.Sprintf("%s%sembed=%s", url, sep, embed)
+	}
+	resp := c.http.HttpGet(url, app)
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return &app.App, nil
+}
+
 func (c *MarathonClient) HasApplication(id string) (bool, error) {
 	app, err := c.GetApplication(id)
 
@@ -261,6 +324,9 @@ func (c *MarathonClient) PauseApplication(id string) (*DeploymentID, error) {
 
 func (c *MarathonClient) ScaleApplication(id string, instances int) (*DeploymentID, error) {
 	log.Infof("Scale Application '%s' to %v instances", id, instances)
+	if err := c.checkGuardrails(id, func(g *Guardrails) []string { return g.CheckScale(id, instances) }); err != nil {
+		return nil, err
+	}
 
 	update := new(Application)
 	update.ID = id
@@ -1,8 +1,12 @@
 package marathon
 
 import (
+	"encoding/json"
+	"github.com/ContainX/depcon/pkg/encoding"
 	"github.com/ContainX/depcon/pkg/mockrest"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"strings"
 	"testing"
 )
 
@@ -122,3 +126,105 @@ func TestNewApplication(t *testing.T) {
 	app := NewApplication("/some/application")
 	assert.Equal(t, "/some/application", app.ID)
 }
+
+func TestApplicationPassthroughRoundTrip(t *testing.T) {
+	data, err := ioutil.ReadFile(AppsFolder + "app_modern.json")
+	assert.Nil(t, err)
+
+	app := new(Application)
+	assert.Nil(t, json.Unmarshal(data, app))
+
+	assert.Equal(t, 1, app.GPUs)
+	assert.Equal(t, KillSelectionOldestFirst, app.KillSelection)
+	assert.Equal(t, 30, app.TaskKillGracePeriod)
+	assert.True(t, app.UnreachableStrategy.Disabled)
+	assert.Equal(t, NetworkModeContainerBridge, app.Networks[0].Mode)
+	assert.Equal(t, "/product/db/password", app.Secrets["db-password"].Source)
+	assert.Equal(t, "pull-config", app.Container.Docker.PullConfig.Secret)
+	assert.Equal(t, 80, app.Container.PortMappings[0].ContainerPort)
+	assert.Equal(t, HealthCheckProtocolMesosHTTP, app.HealthChecks[0].Protocol)
+
+	assert.Contains(t, app.Unknown, "tty")
+	assert.Contains(t, app.Unknown, "resourceLimits")
+	assert.Contains(t, app.Container.Unknown, "linuxInfo")
+	assert.Contains(t, app.Container.Docker.Unknown, "someFutureOption")
+	assert.NotContains(t, app.Unknown, "gpus")
+	assert.Contains(t, app.Networks[0].Unknown, "futureNetworkOption")
+	assert.Contains(t, app.Container.PortMappings[0].Unknown, "futurePortOption")
+	assert.Contains(t, app.Container.Volumes[0].Persistent.Unknown, "type")
+	assert.Contains(t, app.UpgradeStrategy.Unknown, "futureUpgradeOption")
+	assert.Contains(t, app.ReadinessChecks[0].Unknown, "futureReadinessOption")
+	assert.Contains(t, app.Fetch[0].Unknown, "destPath")
+	assert.Contains(t, app.Residency.Unknown, "futureResidencyOption")
+
+	out, err := json.Marshal(app)
+	assert.Nil(t, err)
+
+	var actual map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out, &actual))
+	assert.Equal(t, true, actual["tty"])
+	assert.Equal(t, "disabled", actual["unreachableStrategy"])
+	assert.Equal(t, map[string]interface{}{"cpus": "unlimited"}, actual["resourceLimits"])
+
+	container := actual["container"].(map[string]interface{})
+	assert.Contains(t, container, "linuxInfo")
+	assert.Equal(t, true, container["docker"].(map[string]interface{})["someFutureOption"])
+
+	nested := func(v interface{}, keys ...interface{}) interface{} {
+		for _, k := range keys {
+			if i, ok := k.(int); ok {
+				v = v.([]interface{})[i]
+			} else {
+				v = v.(map[string]interface{})[k.(string)]
+			}
+		}
+		return v
+	}
+	assert.Equal(t, true, nested(actual, "networks", 0, "futureNetworkOption"))
+	assert.Equal(t, "x", nested(container, "portMappings", 0, "futurePortOption"))
+	assert.Equal(t, "mount", nested(container, "volumes", 0, "persistent", "type"))
+	assert.Equal(t, true, nested(actual, "upgradeStrategy", "futureUpgradeOption"))
+	assert.Equal(t, true, nested(actual, "readinessChecks", 0, "futureReadinessOption"))
+	assert.Equal(t, "app", nested(actual, "fetch", 0, "destPath"))
+	assert.Equal(t, float64(1), nested(actual, "residency", "futureResidencyOption"))
+}
+
+func TestParseApplicationStrict(t *testing.T) {
+	opts := &CreateOptions{Strict: true, EnvParams: map[string]string{"NODE_EXPORTER_VERSION": "1"}}
+
+	c := MarathonClient{}
+	app, err := c.ParseApplicationFromFile(AppsFolder+"app_params.json", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "prom/node-exporter:1", app.Container.Docker.Image)
+
+	descriptor := `{"id": "/api", "container": {"docker": {"imag": "api:1.0"}}, "healthcheck": []}`
+	_, err = c.ParseApplicationFromString(strings.NewReader(descriptor), encoding.JSON, opts)
+	errs, ok := err.(encoding.DecodeErrors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "container.docker.imag", errs[0].Path)
+	assert.Equal(t, "image", errs[0].Suggestion)
+	assert.Equal(t, "healthChecks", errs[1].Suggestion)
+
+	opts.Strict = false
+	app, err = c.ParseApplicationFromString(strings.NewReader(descriptor), encoding.JSON, opts)
+	assert.NoError(t, err)
+	assert.Contains(t, app.Unknown, "healthcheck")
+}
+
+func TestParseApplicationStrictSchema(t *testing.T) {
+	descriptor := "id: /api\nhealthChecks:\n  - protocol: HTTPX\n    path: /health\n"
+	opts := &CreateOptions{Strict: true, Source: &encoding.Source{Filename: "api.yaml"}}
+
+	c := MarathonClient{}
+	_, err := c.ParseApplicationFromString(strings.NewReader(descriptor), encoding.YAML, opts)
+	errs, ok := err.(encoding.DecodeErrors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "api.yaml:3:5", errs[0].Location())
+	assert.Contains(t, errs[0].Message, "'HTTPX' must be one of HTTP, HTTPS, TCP")
+
+	schema := ApplicationSchema()
+	assert.Contains(t, schema.Definitions, "HealthCheck")
+	assert.True(t, schema.Definitions["Application"].Properties["tasks"].ReadOnly)
+}
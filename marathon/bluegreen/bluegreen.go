@@ -42,6 +42,12 @@ type BlueGreenOptions struct {
 	EnvParams map[string]string
 	// Do not actually deploy or scale.  Dry run only
 	DryRun bool
+	// Deployment metadata labels stamped on the new application
+	Metadata *marathon.DeployMetadata
+	// If set the application must pass verification before it is deployed
+	Verifier marathon.Verifier
+	// If true descriptors declaring unknown fields are rejected
+	Strict bool
 }
 
 type BGClient struct {
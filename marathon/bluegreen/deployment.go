@@ -36,6 +36,9 @@ func (c *BGClient) DeployBlueGreenFromFile(filename string) (*marathon.Applicati
 	parseOpts := &marathon.CreateOptions{
 		ErrorOnMissingParams: c.opts.ErrorOnMissingParams,
 		EnvParams:            c.opts.EnvParams,
+		Metadata:             c.opts.Metadata,
+		Verifier:             c.opts.Verifier,
+		Strict:               c.opts.Strict,
 	}
 	app, err := c.marathon.ParseApplicationFromFile(filename, parseOpts)
 	if err != nil {
@@ -0,0 +1,240 @@
+package marathon
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Capability string
+
+const (
+	CapabilityPods         Capability = "pods"
+	CapabilityReadiness    Capability = "readiness"
+	CapabilityNetworks     Capability = "networks"
+	CapabilitySecrets      Capability = "secrets"
+	CapabilityDryRunPlans  Capability = "dry-run-plans"
+	CapabilityUnreachable  Capability = "unreachable-strategy"
+	CapabilityKillPolicies Capability = "kill-policies"
+)
+
+// The minimum Marathon version which introduced each capability
+var capabilityVersions = map[Capability]string{
+	CapabilityDryRunPlans:  "0.9.0",
+	CapabilityReadiness:    "1.0.0",
+	CapabilitySecrets:      "1.1.0",
+	CapabilityKillPolicies: "1.3.0",
+	CapabilityPods:         "1.4.0",
+	CapabilityUnreachable:  "1.4.0",
+	CapabilityNetworks:     "1.5.0",
+}
+
+// The capability set of a Marathon server based on its reported version
+type Capabilities struct {
+	Version   string              `json:"version"`
+	Supported map[Capability]bool `json:"supported"`
+}
+
+// Describes a capability and whether the server supports it
+type CapabilityInfo struct {
+	Name       Capability `json:"name"`
+	MinVersion string     `json:"minVersion"`
+	Supported  bool       `json:"supported"`
+}
+
+// Returned when a descriptor declares fields the target server does not support
+type UnsupportedError struct {
+	ID      string
+	Version string
+	Missing []Capability
+}
+
+func (e *UnsupportedError) Error() string {
+	names := make([]string, len(e.Missing))
+	for i, m := range e.Missing {
+		names[i] = fmt.Sprintf("%s (requires %s)", m, capabilityVersions[m])
+	}
+	return fmt.Sprintf("'%s' uses features not supported by Marathon %s: %s", e.ID, e.Version, strings.Join(names, ", "))
+}
+
+// Creates the capability set for the specified Marathon {version}.  Unparsable versions (eg. custom builds)
+// are assumed to support everything
+func NewCapabilities(version string) *Capabilities {
+	c := &Capabilities{Version: version, Supported: map[Capability]bool{}}
+	for cap, min := range capabilityVersions {
+		c.Supported[cap] = compareVersions(version, min) >= 0
+	}
+	return c
+}
+
+func (c *Capabilities) Supports(cap Capability) bool {
+	return c.Supported[cap]
+}
+
+// Returns all known capabilities ordered by name
+func (c *Capabilities) List() []*CapabilityInfo {
+	list := []*CapabilityInfo{}
+	for cap, min := range capabilityVersions {
+		list = append(list, &CapabilityInfo{Name: cap, MinVersion: min, Supported: c.Supports(cap)})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Returns the capabilities from {required} which are not supported
+func (c *Capabilities) Missing(required []Capability) []Capability {
+	missing := []Capability{}
+	for _, r := range required {
+		if !c.Supports(r) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+func (c *MarathonClient) Capabilities() (*Capabilities, error) {
+	c.RLock()
+	caps := c.capabilities
+	c.RUnlock()
+	if caps != nil {
+		return caps, nil
+	}
+
+	info, err := c.GetMarathonInfo()
+	if err != nil {
+		return nil, err
+	}
+	caps = NewCapabilities(info.Version)
+
+	c.Lock()
+	c.capabilities = caps
+	c.Unlock()
+	return caps, nil
+}
+
+// Returns the capabilities required by the fields declared within the application
+func (app *Application) RequiredCapabilities() []Capability {
+	required := []Capability{}
+	if len(app.ReadinessChecks) > 0 {
+		required = append(required, CapabilityReadiness)
+	}
+	if len(app.Networks) > 0 {
+		required = append(required, CapabilityNetworks)
+	}
+	if len(app.Secrets) > 0 {
+		required = append(required, CapabilitySecrets)
+	} else {
+		for _, v := range app.Env {
+			if v.IsSecret() {
+				required = append(required, CapabilitySecrets)
+				break
+			}
+		}
+	}
+	if app.UnreachableStrategy != nil {
+		required = append(required, CapabilityUnreachable)
+	}
+	if app.KillSelection != "" {
+		required = append(required, CapabilityKillPolicies)
+	}
+	return required
+}
+
+// Returns the capabilities required by the group, its applications and sub groups
+func (g *Group) RequiredCapabilities() []Capability {
+	required := []Capability{}
+	if _, ok := g.Unknown["pods"]; ok {
+		required = appendCapability(required, CapabilityPods)
+	}
+	for _, app := range g.Apps {
+		required = appendCapability(required, app.RequiredCapabilities()...)
+	}
+	for _, sg := range g.Groups {
+		required = appendCapability(required, sg.RequiredCapabilities()...)
+	}
+	return required
+}
+
+// Verifies the target server supports the {required} capabilities.  Depending on MarathonOptions
+// an UnsupportedError is returned or a warning is logged
+func (c *MarathonClient) checkCapabilities(id string, required []Capability) error {
+	if len(required) == 0 {
+		return nil
+	}
+
+	caps, err := c.Capabilities()
+	if err != nil {
+		log.Warningf("Unable to determine the Marathon version, skipping feature checks: %s", err.Error())
+		return nil
+	}
+
+	missing := caps.Missing(required)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	uerr := &UnsupportedError{ID: id, Version: caps.Version, Missing: missing}
+	if c.opts != nil && c.opts.FailOnUnsupported {
+		return uerr
+	}
+	c.logOutput(log.Warningf, "%s", uerr.Error())
+	return nil
+}
+
+func appendCapability(arr []Capability, caps ...Capability) []Capability {
+	for _, cap := range caps {
+		found := false
+		for _, a := range arr {
+			if a == cap {
+				found = true
+				break
+			}
+		}
+		if !found {
+			arr = append(arr, cap)
+		}
+	}
+	return arr
+}
+
+// Compares two dotted versions (eg. 1.4.3 or v1.5.0-SNAPSHOT) returning -1, 0 or 1.  If {v1}
+// cannot be parsed it is treated as the newer version
+func compareVersions(v1, v2 string) int {
+	a, ok := parseVersion(v1)
+	if !ok {
+		return 1
+	}
+	b, _ := parseVersion(v2)
+	for i := 0; i < 3; i++ {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var parsed [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+ "); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if v == "" || len(parts) > 3 {
+		return parsed, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
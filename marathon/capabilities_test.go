@@ -0,0 +1,94 @@
+package marathon
+
+import (
+	"fmt"
+	"github.com/ContainX/depcon/pkg/mockrest"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("1.4.0", "1.4.0"))
+	assert.Equal(t, -1, compareVersions("1.3.10", "1.4.0"))
+	assert.Equal(t, 1, compareVersions("v1.5.0-SNAPSHOT", "1.4.0"))
+	assert.Equal(t, 1, compareVersions("1.10", "1.4.0"))
+	assert.Equal(t, 1, compareVersions("custom-build", "1.4.0"))
+}
+
+func TestNewCapabilities(t *testing.T) {
+	caps := NewCapabilities("1.4.2")
+	assert.True(t, caps.Supports(CapabilityPods))
+	assert.True(t, caps.Supports(CapabilitySecrets))
+	assert.False(t, caps.Supports(CapabilityNetworks))
+	assert.True(t, caps.Supports(CapabilityDryRunPlans))
+	assert.Equal(t, []Capability{CapabilityNetworks}, caps.Missing([]Capability{CapabilityReadiness, CapabilityNetworks}))
+}
+
+func TestGroupRequiredCapabilities(t *testing.T) {
+	g := &Group{
+		Apps: []*Application{{Networks: []*Network{{Mode: NetworkModeHost}}}},
+		Groups: []*Group{
+			{Apps: []*Application{{Networks: []*Network{{Mode: NetworkModeHost}}, ReadinessChecks: []*ReadinessCheck{{}}}}},
+		},
+	}
+	assert.Equal(t, []Capability{CapabilityNetworks, CapabilityReadiness}, g.RequiredCapabilities())
+}
+
+func TestCreateApplicationUnsupported(t *testing.T) {
+	s := mockrest.StartNewWithBody(`{"name": "marathon", "version": "1.3.6"}`)
+	defer s.Stop()
+
+	c := NewMarathonClientWithOpts(s.URL, "", "", "", &MarathonOptions{FailOnUnsupported: true})
+	app := &Application{ID: "/product/service", Networks: []*Network{{Mode: NetworkModeContainerBridge}}}
+	_, err := c.CreateApplication(app, false, false)
+
+	assert.IsType(t, &UnsupportedError{}, err)
+	assert.Equal(t, []Capability{CapabilityNetworks}, err.(*UnsupportedError).Missing)
+	assert.Equal(t, "/v2/info", s.TakeRequest().URL.Path)
+}
+
+func TestUpdateApplicationUnsupported(t *testing.T) {
+	s := mockrest.StartNewWithBody(`{"name": "marathon", "version": "1.3.6"}`)
+	defer s.Stop()
+
+	c := NewMarathonClientWithOpts(s.URL, "", "", "", &MarathonOptions{FailOnUnsupported: true})
+	app := &Application{ID: "/product/service", Networks: []*Network{{Mode: NetworkModeContainerBridge}}}
+	_, err := c.UpdateApplication(app, false, false)
+
+	assert.IsType(t, &UnsupportedError{}, err)
+	assert.Equal(t, "/v2/info", s.TakeRequest().URL.Path)
+}
+
+func TestCreateApplicationValidationError(t *testing.T) {
+	s := mockrest.New()
+	s.Start()
+	defer s.Stop()
+	s.Enqueue(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(422)
+		fmt.Fprintln(w, `{"message": "Object is not valid", "details": [{"path": "/cpus", "errors": ["error.min.cpus"]}]}`)
+	})
+
+	c := NewMarathonClient(s.URL, "", "", "")
+	_, err := c.CreateApplication(&Application{ID: "/product/service", CPUs: -1}, false, false)
+
+	assert.IsType(t, &ValidationError{}, err)
+	assert.Contains(t, err.Error(), "/cpus: error.min.cpus")
+}
+
+func TestCreateApplicationValidationErrorWithoutDetails(t *testing.T) {
+	s := mockrest.New()
+	s.Start()
+	defer s.Stop()
+	s.Enqueue(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(422)
+		fmt.Fprintln(w, `{"message": "Object is not valid"}`)
+	})
+
+	c := NewMarathonClient(s.URL, "", "", "")
+	_, err := c.CreateApplication(&Application{ID: "/product/service"}, false, false)
+
+	assert.Error(t, err)
+	assert.NotEqual(t, "*marathon.ValidationError", fmt.Sprintf("%T", err))
+	assert.Contains(t, err.Error(), "Object is not valid")
+}
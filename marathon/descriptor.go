@@ -0,0 +1,113 @@
+package marathon
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Fields reported by Marathon which are not part of an application definition but are not modeled
+// and therefore captured as unknown fields
+var unknownRuntimeFields = []string{"taskStats"}
+
+// Returns a copy of the application containing only its definition.  Runtime state (tasks, deployments,
+// versions, readiness results) and deployment metadata labels are removed along with the deprecated fields
+// Marathon reports alongside their replacements, so the result can be written to a descriptor or sent back
+// to Marathon as an update
+func (app *Application) Descriptor() *Application {
+	d := *app
+	d.Version = ""
+	d.VersionInfo = nil
+	d.Tasks = nil
+	d.TasksRunning = 0
+	d.TasksStaged = 0
+	d.TasksHealthy = 0
+	d.TasksUnHealthy = 0
+	d.DeploymentID = nil
+	d.LastTaskFailure = nil
+	d.ReadinessCheckResults = nil
+	d.Labels = removeMetadataLabels(app.Labels)
+
+	if len(d.PortDefinitions) > 0 {
+		d.Ports = nil
+	}
+	if len(d.Fetch) > 0 {
+		d.Uris = nil
+	}
+
+	if len(app.Unknown) > 0 {
+		d.Unknown = make(map[string]json.RawMessage, len(app.Unknown))
+		for k, v := range app.Unknown {
+			d.Unknown[k] = v
+		}
+		for _, f := range unknownRuntimeFields {
+			delete(d.Unknown, f)
+		}
+	}
+	return &d
+}
+
+// Returns a copy of the group containing only its definition.  See Application.Descriptor
+func (g *Group) Descriptor() *Group {
+	d := *g
+	d.Version = ""
+	d.Apps = make([]*Application, len(g.Apps))
+	for i, app := range g.Apps {
+		d.Apps[i] = app.Descriptor()
+	}
+	d.Groups = make([]*Group, len(g.Groups))
+	for i, sg := range g.Groups {
+		d.Groups[i] = sg.Descriptor()
+	}
+	return &d
+}
+
+// Returns {id} relocated beneath the group {prefix}.  eg. RebaseID("/product/api", "/dr") returns "/dr/product/api"
+func RebaseID(id, prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	id = strings.Trim(id, "/")
+	if prefix == "" {
+		return "/" + id
+	}
+	if id == "" {
+		return "/" + prefix
+	}
+	return "/" + prefix + "/" + id
+}
+
+// Relocates the application and its absolute dependencies beneath the group {prefix}
+func (app *Application) Rebase(prefix string) {
+	app.ID = RebaseID(app.ID, prefix)
+	app.Dependencies = rebaseDependencies(app.Dependencies, prefix)
+}
+
+// Relocates the group, its applications, sub groups and all absolute identifiers beneath the group {prefix}.
+// Relative identifiers remain relative to their (relocated) parent
+func (g *Group) Rebase(prefix string) {
+	if g.GroupID == "" || strings.HasPrefix(g.GroupID, "/") {
+		g.GroupID = RebaseID(g.GroupID, prefix)
+	}
+	g.Dependencies = rebaseDependencies(g.Dependencies, prefix)
+	for _, app := range g.Apps {
+		if strings.HasPrefix(app.ID, "/") {
+			app.ID = RebaseID(app.ID, prefix)
+		}
+		app.Dependencies = rebaseDependencies(app.Dependencies, prefix)
+	}
+	for _, sg := range g.Groups {
+		sg.Rebase(prefix)
+	}
+}
+
+func rebaseDependencies(deps []string, prefix string) []string {
+	if len(deps) == 0 {
+		return deps
+	}
+	rebased := make([]string, len(deps))
+	for i, dep := range deps {
+		if strings.HasPrefix(dep, "/") {
+			dep = RebaseID(dep, prefix)
+		}
+		rebased[i] = dep
+	}
+	return rebased
+}
@@ -0,0 +1,35 @@
+package marathon
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRebaseID(t *testing.T) {
+	assert.Equal(t, "/dr/product/api", RebaseID("/product/api", "/dr"))
+	assert.Equal(t, "/dr/product/api", RebaseID("product/api/", "dr/"))
+	assert.Equal(t, "/product/api", RebaseID("/product/api", "/"))
+	assert.Equal(t, "/dr", RebaseID("/", "/dr"))
+}
+
+func TestGroupRebase(t *testing.T) {
+	g := &Group{
+		GroupID:      "/product",
+		Dependencies: []string{"/infra/db"},
+		Apps: []*Application{
+			{ID: "/product/api", Dependencies: []string{"/infra/db", "cache"}},
+		},
+		Groups: []*Group{
+			{GroupID: "/product/web", Apps: []*Application{{ID: "/product/web/ui"}}},
+		},
+	}
+
+	g.Rebase("/dr/")
+
+	assert.Equal(t, "/dr/product", g.GroupID)
+	assert.Equal(t, []string{"/dr/infra/db"}, g.Dependencies)
+	assert.Equal(t, "/dr/product/api", g.Apps[0].ID)
+	assert.Equal(t, []string{"/dr/infra/db", "cache"}, g.Apps[0].Dependencies)
+	assert.Equal(t, "/dr/product/web", g.Groups[0].GroupID)
+	assert.Equal(t, "/dr/product/web/ui", g.Groups[0].Apps[0].ID)
+}
@@ -0,0 +1,212 @@
+package marathon
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Fields which are populated by Marathon at runtime and never considered when comparing definitions
+var RuntimeFields = []string{
+	"id", "version", "versionInfo", "tasks", "tasksStaged", "tasksRunning", "tasksHealthy", "tasksUnHealthy",
+	"deployments", "lastTaskFailure", "readinessCheckResults", "taskStats",
+}
+
+// Fields compared as a whole so that entries removed from the desired definition are detected
+var exactFields = map[string]bool{"env": true, "labels": true, "secrets": true}
+
+// Port fields where a desired value of 0 asks Marathon to assign a random port
+var portFields = map[string]bool{"ports": true, "servicePorts": true, "port": true, "servicePort": true, "hostPort": true}
+
+// A single difference between a desired and live definition
+type FieldChange struct {
+	Path    string      `json:"path"`
+	Desired interface{} `json:"desired"`
+	Live    interface{} `json:"live"`
+}
+
+func (f *FieldChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", f.Path, f.LiveString(), f.DesiredString())
+}
+
+func (f *FieldChange) DesiredString() string {
+	return diffValueString(f.Desired)
+}
+
+func (f *FieldChange) LiveString() string {
+	return diffValueString(f.Live)
+}
+
+// Compares the {desired} definition against the {live} definition.  Only fields declared within
+// {desired} are compared since Marathon populates defaults for everything else.  Runtime fields,
+// deployment metadata labels and fields matching any of the {ignore} path prefixes are skipped
+func Diff(desired, live interface{}, ignore ...string) ([]*FieldChange, error) {
+	d, err := toGeneric(desired)
+	if err != nil {
+		return nil, err
+	}
+	l, err := toGeneric(live)
+	if err != nil {
+		return nil, err
+	}
+
+	dm, _ := d.(map[string]interface{})
+	for _, f := range RuntimeFields {
+		delete(dm, f)
+	}
+
+	changes := []*FieldChange{}
+	diffValue("", "", d, l, ignore, &changes)
+	return changes, nil
+}
+
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+	if generic == nil {
+		generic = map[string]interface{}{}
+	}
+	return generic, nil
+}
+
+func diffValue(path, key string, desired, live interface{}, ignore []string, changes *[]*FieldChange) {
+	for _, i := range ignore {
+		if path != "" && strings.HasPrefix(path, i) {
+			return
+		}
+	}
+
+	switch dv := desired.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		lv, _ := live.(map[string]interface{})
+		keys := sortedKeys(dv)
+		if exactFields[key] {
+			for _, k := range sortedKeys(lv) {
+				if _, ok := dv[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+		}
+		for _, k := range keys {
+			if key == "labels" && IsMetadataLabel(k) {
+				continue
+			}
+			child := dv[k]
+			if exactFields[key] && child == nil {
+				if lchild, ok := lv[k]; ok {
+					*changes = append(*changes, &FieldChange{Path: path + "/" + k, Live: lchild})
+				}
+				continue
+			}
+			diffValue(path+"/"+k, k, child, lv[k], ignore, changes)
+		}
+	case []interface{}:
+		lv, ok := live.([]interface{})
+		if !ok || len(lv) != len(dv) {
+			*changes = append(*changes, &FieldChange{Path: path, Desired: desired, Live: live})
+			return
+		}
+		before := len(*changes)
+		for i := range dv {
+			diffValue(fmt.Sprintf("%s/%d", path, i), key, dv[i], lv[i], ignore, changes)
+		}
+		if len(*changes) > before && !isObjectSlice(dv) {
+			// report scalar arrays as a single change
+			*changes = append((*changes)[:before], &FieldChange{Path: path, Desired: desired, Live: live})
+		}
+	default:
+		if f, ok := dv.(float64); ok && f == 0 && portFields[key] {
+			return
+		}
+		if !reflect.DeepEqual(desired, live) {
+			*changes = append(*changes, &FieldChange{Path: path, Desired: desired, Live: live})
+		}
+	}
+}
+
+func isObjectSlice(arr []interface{}) bool {
+	for _, v := range arr {
+		if _, ok := v.(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func diffValueString(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// Flattens {v} into a map of JSON paths (eg. /container/docker/image) to leaf values.  Objects are
+// descended into, arrays of objects are indexed and scalar arrays are kept whole.  If {include} path
+// prefixes are specified only matching paths are returned
+func Flatten(v interface{}, include ...string) (map[string]interface{}, error) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	flat := map[string]interface{}{}
+	flattenValue("", generic, include, flat)
+	return flat, nil
+}
+
+func flattenValue(path string, v interface{}, include []string, flat map[string]interface{}) {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		for k, child := range tv {
+			flattenValue(path+"/"+k, child, include, flat)
+		}
+		return
+	case []interface{}:
+		if isObjectSlice(tv) {
+			for i, child := range tv {
+				flattenValue(fmt.Sprintf("%s/%d", path, i), child, include, flat)
+			}
+			return
+		}
+	case nil:
+		return
+	}
+
+	if len(include) == 0 {
+		flat[path] = v
+		return
+	}
+	for _, i := range include {
+		if path == i || strings.HasPrefix(path, i+"/") {
+			flat[path] = v
+			return
+		}
+	}
+}
+
+// Formats a value returned by Diff or Flatten for display
+func FormatValue(v interface{}) string {
+	return diffValueString(v)
+}
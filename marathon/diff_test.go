@@ -0,0 +1,71 @@
+package marathon
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiffOnlyComparesDeclaredFields(t *testing.T) {
+	desired := &Application{ID: "product/service", CPUs: 0.5, Instances: 2, Ports: []int{0}}
+	live := &Application{ID: "/product/service", CPUs: 0.5, Instances: 2, Mem: 128, Ports: []int{10001},
+		Version: "2017-06-01T10:00:00.000Z", TasksRunning: 2}
+
+	changes, err := Diff(desired, live)
+	assert.Nil(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDiffDetectsChanges(t *testing.T) {
+	desired := &Application{
+		Instances: 3,
+		Env:       map[string]EnvVar{"LOG_LEVEL": EnvValue("info")},
+		Container: &Container{Docker: &Docker{Image: "nginx:1.13"}},
+	}
+	live := &Application{
+		Instances: 2,
+		Env:       map[string]EnvVar{"LOG_LEVEL": EnvValue("info"), "DEBUG": EnvValue("true")},
+		Container: &Container{Type: ContainerTypeDocker, Docker: &Docker{Image: "nginx:1.12"}},
+	}
+
+	changes, err := Diff(desired, live)
+	assert.Nil(t, err)
+
+	paths := []string{}
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	assert.Equal(t, []string{"/container/docker/image", "/env/DEBUG", "/instances"}, paths)
+	assert.Equal(t, "/container/docker/image: nginx:1.12 -> nginx:1.13", changes[0].String())
+}
+
+func TestGroupFlattenApps(t *testing.T) {
+	g := &Group{
+		GroupID: "/product",
+		Apps:    []*Application{{ID: "api"}},
+		Groups:  []*Group{{GroupID: "workers", Apps: []*Application{{ID: "/product/workers/queue"}, {ID: "cron"}}}},
+	}
+
+	ids := []string{}
+	for _, app := range g.FlattenApps() {
+		ids = append(ids, app.ID)
+	}
+	assert.Equal(t, []string{"/product/api", "/product/workers/queue", "/product/workers/cron"}, ids)
+	assert.Equal(t, "api", g.Apps[0].ID, "original should not be modified")
+}
+
+func TestFlatten(t *testing.T) {
+	app := &Application{
+		CPUs:         0.5,
+		Container:    &Container{Docker: &Docker{Image: "nginx:1.13"}},
+		Constraints:  [][]string{{"hostname", "UNIQUE"}},
+		HealthChecks: []*HealthCheck{{Protocol: HealthCheckProtocolHTTP, Path: "/health"}},
+	}
+
+	flat, err := Flatten(app, "/container/docker/image", "/cpus", "/constraints", "/healthChecks")
+	assert.Nil(t, err)
+	assert.Equal(t, "nginx:1.13", flat["/container/docker/image"])
+	assert.Equal(t, 0.5, flat["/cpus"])
+	assert.Equal(t, `[["hostname","UNIQUE"]]`, FormatValue(flat["/constraints"]))
+	assert.Equal(t, "/health", flat["/healthChecks/0/path"])
+	assert.NotContains(t, flat, "/fetch")
+}
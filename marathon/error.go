@@ -1,10 +1,55 @@
 package marathon
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 )
 
 var (
 	ErrorTimeout            = errors.New("The operation has timed out")
 	ErrorDeploymentNotfound = errors.New("Failed to get deployment in allocated time")
 )
+
+// A 422 (Unprocessable Entity) response describing why Marathon rejected a descriptor
+type ValidationError struct {
+	Message string              `json:"message"`
+	Details []*ValidationDetail `json:"details"`
+}
+
+type ValidationDetail struct {
+	Path   string   `json:"path"`
+	Errors []string `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	buf := bytes.NewBufferString("Marathon rejected the definition: ")
+	if e.Message != "" {
+		buf.WriteString(e.Message)
+	} else {
+		buf.WriteString("Object is not valid")
+	}
+	for _, d := range e.Details {
+		path := d.Path
+		if path == "" || path == "/" {
+			path = "(root)"
+		}
+		fmt.Fprintf(buf, "\n  %s: %s", path, strings.Join(d.Errors, ", "))
+	}
+	return buf.String()
+}
+
+// Parses a 422 response body into a ValidationError.  If the body is not a Marathon
+// validation message then nil is returned
+func parseValidationError(content string) *ValidationError {
+	verr := new(ValidationError)
+	if err := json.Unmarshal([]byte(content), verr); err != nil {
+		return nil
+	}
+	if verr.Message == "" && len(verr.Details) == 0 {
+		return nil
+	}
+	return verr
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/ContainX/depcon/pkg/encoding"
 	"github.com/ContainX/depcon/pkg/envsubst"
 	"github.com/ContainX/depcon/pkg/httpclient"
+	"github.com/ContainX/depcon/utils"
 	"io"
 	"os"
 	"strings"
@@ -61,7 +62,7 @@ func (c *MarathonClient) ParseGroupFromFile(filename string, opts *CreateOptions
 	if et, err := encoding.EncoderTypeFromExt(filename); err != nil {
 		return nil, err
 	} else {
-		return c.ParseGroupFromString(file, et, opts)
+		return c.ParseGroupFromString(file, et, withSource(opts, filename))
 	}
 }
 
@@ -89,15 +90,41 @@ func (c *MarathonClient) ParseGroupFromString(r io.Reader, et encoding.EncoderTy
 	}
 
 	group := new(Group)
-	err = encoder.UnMarshalStr(parsed, &group)
+	if options.Strict {
+		if err = encoding.UnMarshalStrict(et, parsed, group, options.Source); err == nil {
+			err = validateSchema(GroupSchema(), et, parsed, options.Source)
+		}
+	} else {
+		err = encoder.UnMarshalStr(parsed, &group)
+	}
 	if err != nil {
 		return nil, err
 	}
+	for _, app := range group.FlattenApps() {
+		if err := app.checkSecrets(); err != nil {
+			return nil, err
+		}
+	}
+	if options.Metadata != nil {
+		group.StampMetadata(options.Metadata)
+	}
+	if options.Verifier != nil {
+		if err := options.Verifier.VerifyGroup(group); err != nil {
+			return nil, err
+		}
+	}
 	return group, nil
 }
 
 func (c *MarathonClient) CreateGroup(group *Group, wait, force bool) (*Group, error) {
 	c.logOutput(log.Infof, "Creating Group '%s', wait: %v, force: %v", group.GroupID, wait, force)
+
+	if err := c.checkCapabilities(group.GroupID, group.RequiredCapabilities()); err != nil {
+		return nil, err
+	}
+	if err := c.checkGuardrails(group.GroupID, func(g *Guardrails) []string { return g.CheckGroup(group) }); err != nil {
+		return nil, err
+	}
 	result := new(DeploymentID)
 	resp := c.http.HttpPost(c.marathonUrl(API_GROUPS), group, result)
 	if resp.Error != nil {
@@ -109,6 +136,9 @@ func (c *MarathonClient) CreateGroup(group *Group, wait, force bool) (*Group, er
 				return nil, ErrorGroupExists
 			}
 			if resp.Status == 422 {
+				if verr := parseValidationError(resp.Content); verr != nil && len(verr.Details) > 0 {
+					return nil, verr
+				}
 				return nil, ErrorInvalidGroupId
 			}
 			return nil, fmt.Errorf("Error occurred (Status %v) Body -> %s", resp.Status, resp.Content)
@@ -125,12 +155,21 @@ func (c *MarathonClient) CreateGroup(group *Group, wait, force bool) (*Group, er
 
 func (c *MarathonClient) UpdateGroup(group *Group, wait bool) (*Group, error) {
 	log.Info("Update Group '%s', wait = %v", group.GroupID, wait)
+	if err := c.checkCapabilities(group.GroupID, group.RequiredCapabilities()); err != nil {
+		return nil, err
+	}
+	if err := c.checkGuardrails(group.GroupID, func(g *Guardrails) []string { return g.CheckGroup(group) }); err != nil {
+		return nil, err
+	}
 	result := new(DeploymentID)
 	resp := c.http.HttpPut(c.marathonUrl(API_GROUPS), group, result)
 
 	if resp.Error != nil {
 		if resp.Error == httpclient.ErrorMessage {
 			if resp.Status == 422 {
+				if verr := parseValidationError(resp.Content); verr != nil && len(verr.Details) > 0 {
+					return nil, verr
+				}
 				return nil, ErrorGroupAppExists
 			}
 		}
@@ -172,3 +211,31 @@ func (c *MarathonClient) DestroyGroup(id string) (*DeploymentID, error) {
 	}
 	return deploymentId, nil
 }
+
+// Returns copies of all applications within the group and its sub groups with their identifiers
+// resolved to absolute paths
+func (g *Group) FlattenApps() []*Application {
+	return g.flattenApps(utils.TrimRootPath(g.GroupID), []*Application{})
+}
+
+func (g *Group) flattenApps(parent string, arr []*Application) []*Application {
+	for _, app := range g.Apps {
+		a := *app
+		a.ID = resolveID(parent, app.ID)
+		arr = append(arr, &a)
+	}
+	for _, sg := range g.Groups {
+		arr = sg.flattenApps(utils.TrimRootPath(resolveID(parent, sg.GroupID)), arr)
+	}
+	return arr
+}
+
+func resolveID(parent, id string) string {
+	if strings.HasPrefix(id, "/") {
+		return id
+	}
+	if parent == "" {
+		return "/" + id
+	}
+	return "/" + parent + "/" + id
+}
@@ -0,0 +1,154 @@
+package marathon
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultRegistry = "docker.io"
+)
+
+// Environment level limits which every application and group must satisfy before it is created, updated
+// or scaled.  Zero values are not enforced
+type Guardrails struct {
+	// Max instances of a single application
+	MaxInstances int `json:"maxInstances,omitempty"`
+	// Max cpus of a single instance
+	MaxCPUs float64 `json:"maxCpus,omitempty"`
+	// Max memory (MB) of a single instance
+	MaxMem float64 `json:"maxMem,omitempty"`
+	// Max total instances of all applications within a group
+	MaxGroupInstances int `json:"maxGroupInstances,omitempty"`
+	// Max total cpus (cpus * instances) of all applications within a group
+	MaxGroupCPUs float64 `json:"maxGroupCpus,omitempty"`
+	// Max total memory (mem * instances) of all applications within a group
+	MaxGroupMem float64 `json:"maxGroupMem,omitempty"`
+	// Labels every application must declare with a value
+	RequiredLabels []string `json:"requiredLabels,omitempty"`
+	// Registries (or registry/namespace prefixes) docker images must be pulled from.  Images without a
+	// registry are from docker.io
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+}
+
+// Returned when an application or group violates the guardrails of the environment
+type GuardrailError struct {
+	ID         string
+	Violations []string
+}
+
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("'%s' violates the environment guardrails:\n  - %s", e.ID, strings.Join(e.Violations, "\n  - "))
+}
+
+// Returns the guardrail violations of {app}
+func (g *Guardrails) CheckApplication(app *Application) []string {
+	violations := []string{}
+	if g.MaxInstances > 0 && app.Instances > g.MaxInstances {
+		violations = append(violations, fmt.Sprintf("%s: instances %d exceeds the max of %d", app.ID, app.Instances, g.MaxInstances))
+	}
+	if g.MaxCPUs > 0 && app.CPUs > g.MaxCPUs {
+		violations = append(violations, fmt.Sprintf("%s: cpus %g exceeds the max of %g per instance", app.ID, app.CPUs, g.MaxCPUs))
+	}
+	if g.MaxMem > 0 && app.Mem > g.MaxMem {
+		violations = append(violations, fmt.Sprintf("%s: mem %g exceeds the max of %g per instance", app.ID, app.Mem, g.MaxMem))
+	}
+	for _, label := range g.RequiredLabels {
+		if app.Labels[label] == "" {
+			violations = append(violations, fmt.Sprintf("%s: missing required label '%s'", app.ID, label))
+		}
+	}
+	if len(g.AllowedRegistries) > 0 && app.Container != nil && app.Container.Docker != nil && app.Container.Docker.Image != "" {
+		if image := app.Container.Docker.Image; !g.registryAllowed(image) {
+			violations = append(violations, fmt.Sprintf("%s: image '%s' is not from an allowed registry (%s)", app.ID, image,
+				strings.Join(g.AllowedRegistries, ", ")))
+		}
+	}
+	return violations
+}
+
+// Returns the guardrail violations of the (possibly partial) application update {app}.  Required labels
+// are only verified if the update replaces the labels
+func (g *Guardrails) CheckUpdate(app *Application) []string {
+	if app.Labels != nil {
+		return g.CheckApplication(app)
+	}
+	partial := *g
+	partial.RequiredLabels = nil
+	return partial.CheckApplication(app)
+}
+
+// Returns the guardrail violations of every application within {group} and of the group's total footprint
+func (g *Guardrails) CheckGroup(group *Group) []string {
+	violations := []string{}
+	instances, cpus, mem := 0, 0.0, 0.0
+	for _, app := range group.FlattenApps() {
+		violations = append(violations, g.CheckApplication(app)...)
+		instances += app.Instances
+		cpus += app.CPUs * float64(app.Instances)
+		mem += app.Mem * float64(app.Instances)
+	}
+	if g.MaxGroupInstances > 0 && instances > g.MaxGroupInstances {
+		violations = append(violations, fmt.Sprintf("%s: total instances %d exceeds the group max of %d", group.GroupID, instances, g.MaxGroupInstances))
+	}
+	if g.MaxGroupCPUs > 0 && cpus > g.MaxGroupCPUs {
+		violations = append(violations, fmt.Sprintf("%s: total cpus %g exceeds the group max of %g", group.GroupID, cpus, g.MaxGroupCPUs))
+	}
+	if g.MaxGroupMem > 0 && mem > g.MaxGroupMem {
+		violations = append(violations, fmt.Sprintf("%s: total mem %g exceeds the group max of %g", group.GroupID, mem, g.MaxGroupMem))
+	}
+	return violations
+}
+
+// Returns the guardrail violations of scaling the application {id} to {instances}
+func (g *Guardrails) CheckScale(id string, instances int) []string {
+	if g.MaxInstances > 0 && instances > g.MaxInstances {
+		return []string{fmt.Sprintf("%s: instances %d exceeds the max of %d", id, instances, g.MaxInstances)}
+	}
+	return []string{}
+}
+
+func (g *Guardrails) registryAllowed(image string) bool {
+	registry := ImageRegistry(image)
+	for _, allowed := range g.AllowedRegistries {
+		allowed = strings.TrimSuffix(allowed, "/")
+		if registry == allowed || strings.HasPrefix(qualifiedImage(image), allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the registry of the docker {image}.  eg. registry.example.com:5000/team/api:1.0 returns
+// registry.example.com:5000 and nginx:latest returns docker.io
+func ImageRegistry(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+	return DefaultRegistry
+}
+
+// Returns {image} prefixed with its registry.  Official docker.io images are within the library namespace
+func qualifiedImage(image string) string {
+	registry := ImageRegistry(image)
+	if strings.HasPrefix(image, registry+"/") {
+		return image
+	}
+	if !strings.Contains(image, "/") {
+		image = "library/" + image
+	}
+	return registry + "/" + image
+}
+
+// Verifies the application or group against the guardrails within MarathonOptions if specified.  The
+// lock marker apps within LockGroup are not user descriptors and are never checked
+func (c *MarathonClient) checkGuardrails(id string, check func(g *Guardrails) []string) error {
+	if c.opts == nil || c.opts.Guardrails == nil || isLockID(id) {
+		return nil
+	}
+	if violations := check(c.opts.Guardrails); len(violations) > 0 {
+		return &GuardrailError{ID: id, Violations: violations}
+	}
+	return nil
+}
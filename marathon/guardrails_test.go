@@ -0,0 +1,46 @@
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardrailsCheckApplication(t *testing.T) {
+	g := &Guardrails{MaxInstances: 10, MaxCPUs: 2, MaxMem: 2048, RequiredLabels: []string{"team"},
+		AllowedRegistries: []string{"registry.example.com", "docker.io/library"}}
+
+	app := &Application{ID: "/product/api", Instances: 3, CPUs: 1, Mem: 512, Labels: map[string]string{"team": "core"},
+		Container: &Container{Docker: &Docker{Image: "registry.example.com/product/api:1.0"}}}
+	assert.Empty(t, g.CheckApplication(app))
+
+	app.Container.Docker.Image = "nginx:latest"
+	assert.Empty(t, g.CheckApplication(app))
+
+	app.Instances, app.Mem, app.Labels = 100, 8192, nil
+	app.Container.Docker.Image = "evil.io/miner:latest"
+	assert.Len(t, g.CheckApplication(app), 4)
+
+	// partial updates only verify labels when they are replaced
+	assert.Len(t, g.CheckUpdate(&Application{ID: "/product/api", CPUs: 1}), 0)
+}
+
+func TestGuardrailsCheckGroup(t *testing.T) {
+	g := &Guardrails{MaxGroupInstances: 5, MaxGroupMem: 1024}
+	group := &Group{GroupID: "/product", Apps: []*Application{
+		{ID: "/product/api", Instances: 3, Mem: 256},
+		{ID: "/product/web", Instances: 3, Mem: 128},
+	}}
+
+	violations := g.CheckGroup(group)
+	assert.Len(t, violations, 2)
+	assert.Contains(t, violations[0], "total instances 6")
+	assert.Contains(t, violations[1], "total mem 1152")
+}
+
+func TestImageRegistry(t *testing.T) {
+	assert.Equal(t, "docker.io", ImageRegistry("nginx"))
+	assert.Equal(t, "docker.io", ImageRegistry("containx/depcon:latest"))
+	assert.Equal(t, "registry.example.com:5000", ImageRegistry("registry.example.com:5000/team/api:1.0"))
+	assert.Equal(t, "localhost", ImageRegistry("localhost/api"))
+}
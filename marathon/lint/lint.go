@@ -0,0 +1,319 @@
+// Checks application and group descriptors against a set of best practice rules
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/marathon/bluegreen"
+	"github.com/ContainX/depcon/utils"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+
+	RuleHealthCheck          = "health-check"
+	RuleUpgradeStrategy      = "upgrade-strategy"
+	RuleLatestTag            = "latest-tag"
+	RuleJVMMemory            = "jvm-memory"
+	RulePortName             = "port-name"
+	RuleBlueGreenLabels      = "bluegreen-labels"
+	RuleDuplicateServicePort = "duplicate-service-port"
+	RuleConstraintOperator   = "constraint-operator"
+
+	// Minimum memory (MB) for an application running a JVM
+	MinJVMMemory = 512
+)
+
+var (
+	constraintOperators = []string{"UNIQUE", "CLUSTER", "GROUP_BY", "LIKE", "UNLIKE", "MAX_PER", "IS"}
+	javaCmdRegex        = regexp.MustCompile(`(^|[\s/])java(\s|$)`)
+	xmxRegex            = regexp.MustCompile(`-Xmx(\d+)([kKmMgG]?)`)
+)
+
+// A single rule violation
+type Issue struct {
+	File     string `json:"file,omitempty"`
+	ID       string `json:"id"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	// Path of the offending field within the application (eg. container.docker.image)
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// A lint rule.  Check returns the violations of an application
+type Rule struct {
+	ID          string `json:"id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	check       func(l *Linter, app *marathon.Application) []*Issue
+}
+
+type Options struct {
+	// Rule ids which are not checked
+	Disabled []string
+	// If true every application is checked for blue/green deployment labels, otherwise only those
+	// already declaring HAPROXY_* labels
+	BlueGreen bool
+}
+
+// Lints applications.  Service ports are tracked across every application linted so duplicates
+// between descriptors are reported
+type Linter struct {
+	opts         *Options
+	servicePorts map[int]string
+}
+
+var rules = []*Rule{
+	{RuleHealthCheck, SeverityWarning, "Applications should declare at least one health check", checkHealthCheck},
+	{RuleUpgradeStrategy, SeverityInfo, "Applications should declare an upgradeStrategy", checkUpgradeStrategy},
+	{RuleLatestTag, SeverityError, "Docker images should be pinned to a tag other than latest", checkLatestTag},
+	{RuleJVMMemory, SeverityWarning, "JVM applications need enough memory for their heap and overhead", checkJVMMemory},
+	{RulePortName, SeverityInfo, "Ports should be named", checkPortNames},
+	{RuleBlueGreenLabels, SeverityError, "Blue/green deployments require the HAPROXY_DEPLOYMENT_* labels and a service port", checkBlueGreenLabels},
+	{RuleDuplicateServicePort, SeverityError, "Service ports must be unique across applications", checkDuplicateServicePorts},
+	{RuleConstraintOperator, SeverityError, "Constraints must use a valid operator", checkConstraints},
+}
+
+// Returns all available rules
+func Rules() []*Rule {
+	return rules
+}
+
+func New(opts *Options) *Linter {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Linter{opts: opts, servicePorts: map[int]string{}}
+}
+
+// Returns the issues of {app} ordered by severity and rule
+func (l *Linter) LintApplication(app *marathon.Application) []*Issue {
+	issues := []*Issue{}
+	for _, r := range rules {
+		if utils.StringInSlice(r.ID, l.opts.Disabled) {
+			continue
+		}
+		for _, i := range r.check(l, app) {
+			i.ID, i.Rule, i.Severity = app.ID, r.ID, r.Severity
+			issues = append(issues, i)
+		}
+	}
+	Sort(issues)
+	return issues
+}
+
+// Returns the issues of every application within {group}
+func (l *Linter) LintGroup(group *marathon.Group) []*Issue {
+	issues := []*Issue{}
+	for _, app := range group.FlattenApps() {
+		issues = append(issues, l.LintApplication(app)...)
+	}
+	return issues
+}
+
+// Returns true if any of the {issues} is an error
+func HasErrors(issues []*Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Orders {issues} by severity (errors first) and then rule
+func Sort(issues []*Issue) {
+	rank := map[string]int{SeverityError: 0, SeverityWarning: 1, SeverityInfo: 2}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if rank[issues[i].Severity] != rank[issues[j].Severity] {
+			return rank[issues[i].Severity] < rank[issues[j].Severity]
+		}
+		return issues[i].Rule < issues[j].Rule
+	})
+}
+
+func issue(path, format string, args ...interface{}) *Issue {
+	return &Issue{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+func checkHealthCheck(l *Linter, app *marathon.Application) []*Issue {
+	if len(app.HealthChecks) == 0 {
+		return []*Issue{issue("healthChecks", "no health checks are declared so failed tasks will not be replaced")}
+	}
+	return nil
+}
+
+func checkUpgradeStrategy(l *Linter, app *marathon.Application) []*Issue {
+	if app.UpgradeStrategy == nil {
+		return []*Issue{issue("upgradeStrategy", "no upgradeStrategy is declared, Marathon defaults apply")}
+	}
+	return nil
+}
+
+func checkLatestTag(l *Linter, app *marathon.Application) []*Issue {
+	if app.Container == nil || app.Container.Docker == nil || app.Container.Docker.Image == "" {
+		return nil
+	}
+	image := app.Container.Docker.Image
+	if strings.Contains(image, "@") {
+		// pinned by digest
+		return nil
+	}
+	if i := strings.LastIndex(image, ":"); i < 0 || strings.Contains(image[i:], "/") || image[i+1:] == "latest" {
+		return []*Issue{issue("container.docker.image", "image '%s' uses the latest tag", image)}
+	}
+	return nil
+}
+
+func checkJVMMemory(l *Linter, app *marathon.Application) []*Issue {
+	cmd := strings.Join(append([]string{app.Cmd}, app.Args...), " ")
+	if !javaCmdRegex.MatchString(cmd) && !strings.Contains(cmd, "-jar ") {
+		return nil
+	}
+	if m := xmxRegex.FindStringSubmatch(cmd); m != nil {
+		if heap := heapMB(m[1], m[2]); heap >= app.Mem {
+			return []*Issue{issue("mem", "mem %g is not greater than the JVM heap -Xmx%s%s", app.Mem, m[1], m[2])}
+		}
+	}
+	if app.Mem < MinJVMMemory {
+		return []*Issue{issue("mem", "mem %g is likely too low for a JVM (min %d)", app.Mem, MinJVMMemory)}
+	}
+	return nil
+}
+
+func heapMB(value, unit string) float64 {
+	v, _ := strconv.ParseFloat(value, 64)
+	switch strings.ToLower(unit) {
+	case "k":
+		return v / 1024
+	case "g":
+		return v * 1024
+	case "m":
+		return v
+	}
+	return v / 1024 / 1024
+}
+
+func checkPortNames(l *Linter, app *marathon.Application) []*Issue {
+	issues := []*Issue{}
+	for i, p := range app.PortDefinitions {
+		if p.Name == "" {
+			issues = append(issues, issue(fmt.Sprintf("portDefinitions[%d].name", i), "port %d has no name", p.Port))
+		}
+	}
+	for _, m := range portMappings(app) {
+		if m.Name == "" {
+			issues = append(issues, issue(m.path+".name", "container port %d has no name", m.ContainerPort))
+		}
+	}
+	return issues
+}
+
+func checkBlueGreenLabels(l *Linter, app *marathon.Application) []*Issue {
+	if !l.opts.BlueGreen && !hasHAProxyLabels(app) {
+		return nil
+	}
+	issues := []*Issue{}
+	for _, label := range []string{bluegreen.DeployGroup, bluegreen.DeployGroupAltPort} {
+		if app.Labels[label] == "" {
+			issues = append(issues, issue("labels."+label, "label %s is required for blue/green deployments", label))
+		}
+	}
+	if len(servicePorts(app)) == 0 {
+		issues = append(issues, issue("servicePorts", "a service port is required for blue/green deployments"))
+	}
+	return issues
+}
+
+func checkDuplicateServicePorts(l *Linter, app *marathon.Application) []*Issue {
+	issues := []*Issue{}
+	seen := map[int]bool{}
+	for _, sp := range servicePorts(app) {
+		if seen[sp.port] {
+			// the same port is commonly declared by both servicePorts and the port mappings
+			continue
+		}
+		seen[sp.port] = true
+		if owner, ok := l.servicePorts[sp.port]; ok && owner != app.ID {
+			issues = append(issues, issue(sp.path, "service port %d is also used by %s", sp.port, owner))
+			continue
+		}
+		l.servicePorts[sp.port] = app.ID
+	}
+	return issues
+}
+
+func checkConstraints(l *Linter, app *marathon.Application) []*Issue {
+	issues := []*Issue{}
+	for i, c := range app.Constraints {
+		if len(c) < 2 {
+			issues = append(issues, issue(fmt.Sprintf("constraints[%d]", i), "constraint %v requires a field and operator", c))
+			continue
+		}
+		if !utils.StringInSlice(strings.ToUpper(c[1]), constraintOperators) {
+			issues = append(issues, issue(fmt.Sprintf("constraints[%d][1]", i), "invalid operator '%s', must be one of %s", c[1],
+				strings.Join(constraintOperators, ", ")))
+		}
+	}
+	return issues
+}
+
+func hasHAProxyLabels(app *marathon.Application) bool {
+	for k := range app.Labels {
+		if strings.HasPrefix(k, "HAPROXY_") {
+			return true
+		}
+	}
+	return false
+}
+
+type pathPortMapping struct {
+	*marathon.PortMapping
+	path string
+}
+
+type servicePort struct {
+	path string
+	port int
+}
+
+// Returns the container and docker port mappings of the app along with their paths
+func portMappings(app *marathon.Application) []*pathPortMapping {
+	result := []*pathPortMapping{}
+	if app.Container == nil {
+		return result
+	}
+	for i, p := range app.Container.PortMappings {
+		result = append(result, &pathPortMapping{p, fmt.Sprintf("container.portMappings[%d]", i)})
+	}
+	if app.Container.Docker != nil {
+		for i, p := range app.Container.Docker.PortMappings {
+			result = append(result, &pathPortMapping{p, fmt.Sprintf("container.docker.portMappings[%d]", i)})
+		}
+	}
+	return result
+}
+
+// Returns the non zero service ports declared by the app along with their paths
+func servicePorts(app *marathon.Application) []*servicePort {
+	result := []*servicePort{}
+	for i, p := range app.ServicePorts {
+		if p > 0 {
+			result = append(result, &servicePort{fmt.Sprintf("servicePorts[%d]", i), p})
+		}
+	}
+	for _, m := range portMappings(app) {
+		if m.ServicePort > 0 {
+			result = append(result, &servicePort{m.path + ".servicePort", m.ServicePort})
+		}
+	}
+	return result
+}
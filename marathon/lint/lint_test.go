@@ -0,0 +1,54 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/stretchr/testify/assert"
+)
+
+func rulesOf(issues []*Issue) []string {
+	ids := []string{}
+	for _, i := range issues {
+		ids = append(ids, i.Rule)
+	}
+	return ids
+}
+
+func TestLintApplication(t *testing.T) {
+	app := &marathon.Application{
+		ID:          "/product/api",
+		Cmd:         "java -Xmx1g -jar api.jar",
+		Mem:         768,
+		Constraints: [][]string{{"hostname", "UNIQUE"}, {"rack", "SPREAD"}},
+		Container: &marathon.Container{Docker: &marathon.Docker{
+			Image:        "product/api",
+			PortMappings: []*marathon.PortMapping{{ContainerPort: 8080, ServicePort: 10000}},
+		}},
+		Labels: map[string]string{"HAPROXY_GROUP": "external"},
+	}
+
+	issues := New(nil).LintApplication(app)
+	assert.Equal(t, []string{RuleBlueGreenLabels, RuleBlueGreenLabels, RuleConstraintOperator, RuleLatestTag,
+		RuleHealthCheck, RuleJVMMemory, RulePortName, RuleUpgradeStrategy}, rulesOf(issues))
+	assert.Equal(t, "constraints[1][1]", issues[2].Path)
+	assert.Equal(t, "container.docker.image", issues[3].Path)
+	assert.Equal(t, "container.docker.portMappings[0].name", issues[6].Path)
+	assert.True(t, HasErrors(issues))
+}
+
+func TestLintDisabledAndDuplicatePorts(t *testing.T) {
+	l := New(&Options{Disabled: []string{RuleHealthCheck, RuleUpgradeStrategy, RulePortName}})
+	group := &marathon.Group{GroupID: "/product", Apps: []*marathon.Application{
+		{ID: "/product/api", ServicePorts: []int{10000}, Container: &marathon.Container{Docker: &marathon.Docker{
+			Image:        "product/api:1.0",
+			PortMappings: []*marathon.PortMapping{{Name: "http", ContainerPort: 8080, ServicePort: 10000}},
+		}}},
+		{ID: "/product/web", ServicePorts: []int{10000}},
+	}}
+
+	issues := l.LintGroup(group)
+	assert.Equal(t, []string{RuleDuplicateServicePort}, rulesOf(issues))
+	assert.Equal(t, "/product/web", issues[0].ID)
+	assert.Equal(t, "servicePorts[0]", issues[0].Path)
+}
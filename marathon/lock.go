@@ -0,0 +1,284 @@
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ContainX/depcon/pkg/httpclient"
+	"github.com/ContainX/depcon/utils"
+)
+
+const (
+	// The group containing the lock marker apps
+	LockGroup = "/depcon-locks"
+
+	LabelLockTarget   = MetadataLabelPrefix + "lock-target"
+	LabelLockOwner    = MetadataLabelPrefix + "lock-owner"
+	LabelLockReason   = MetadataLabelPrefix + "lock-reason"
+	LabelLockAcquired = MetadataLabelPrefix + "lock-acquired"
+	LabelLockExpires  = MetadataLabelPrefix + "lock-expires"
+
+	lockPollInterval = time.Second * 5
+)
+
+var (
+	ErrorLockNotHeld  = errors.New("No lock is held for the specified app or group")
+	ErrorLockNotOwned = errors.New("The lock is owned by someone else.  Use 'lock break' to forcibly remove it")
+)
+
+// An advisory deployment lock on an app or group.  Locks are stored as a marker application (with no
+// instances) under the LockGroup so that only one lock can be created per target
+type Lock struct {
+	Target   string    `json:"target"`
+	Owner    string    `json:"owner"`
+	Reason   string    `json:"reason,omitempty"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
+
+// Returned when a lock cannot be acquired because it (or a lock on a parent or child) is held
+type LockedError struct {
+	Lock *Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("'%s' is locked by %s until %s (%s)", e.Lock.Target, e.Lock.Owner,
+		e.Lock.Expires.Local().Format("2006-01-02 15:04:05"), e.Lock.Reason)
+}
+
+func (l *Lock) Expired() bool {
+	return time.Now().After(l.Expires)
+}
+
+// Returns true if this lock covers {target}: the same id, a parent group or a child
+func (l *Lock) Covers(target string) bool {
+	a, b := utils.TrimRootPath(l.Target), utils.TrimRootPath(target)
+	return a == b || strings.HasPrefix(b, a+"/") || strings.HasPrefix(a, b+"/")
+}
+
+// Acquires a lock on {target} for {owner} which expires after {ttl}.  Expired locks are broken and a lock
+// already held by {owner} is returned as is.  If the target, a parent or a child is locked by someone else
+// a *LockedError is returned.  Creating the marker app is atomic for the target itself.  Parent and child
+// locks are checked again once created and the later of two conflicting locks backs off
+func AcquireLock(c Marathon, target, owner, reason string, ttl time.Duration) (*Lock, error) {
+	locks, err := ListLocks(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range locks {
+		if !l.Covers(target) {
+			continue
+		}
+		if l.Expired() {
+			if err := breakExpiredLock(c, l); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if l.Owner != owner {
+			return nil, &LockedError{Lock: l}
+		}
+		if utils.TrimRootPath(l.Target) == utils.TrimRootPath(target) {
+			// already held by the owner
+			return l, nil
+		}
+	}
+
+	// truncated to the precision of the labels so it can be compared with listed locks
+	now := time.Now().UTC().Truncate(time.Second)
+	lock := &Lock{Target: "/" + utils.TrimRootPath(target), Owner: owner, Reason: reason, Acquired: now, Expires: now.Add(ttl)}
+	if err := c.CreateLockMarker(lock); err != nil {
+		if err == ErrorAppExists {
+			if existing, gerr := GetLock(c, target); gerr == nil {
+				return nil, &LockedError{Lock: existing}
+			}
+		}
+		return nil, err
+	}
+
+	if conflict, err := conflictingLock(c, lock); err != nil || conflict != nil {
+		BreakLock(c, lock.Target)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &LockedError{Lock: conflict}
+	}
+	return lock, nil
+}
+
+// Breaks the expired lock {l} unless it has been re-acquired since it was listed
+func breakExpiredLock(c Marathon, l *Lock) error {
+	current, err := GetLock(c, l.Target)
+	if err == ErrorLockNotHeld {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if current.Owner != l.Owner || !current.Expires.Equal(l.Expires) {
+		if current.Expired() {
+			return breakExpiredLock(c, current)
+		}
+		return &LockedError{Lock: current}
+	}
+	if err := BreakLock(c, l.Target); err != nil && err != ErrorLockNotHeld {
+		return err
+	}
+	return nil
+}
+
+// Returns a parent or child lock of {lock} held by someone else which was acquired first.  Ties are
+// decided by the target so that exactly one of two conflicting locks backs off
+func conflictingLock(c Marathon, lock *Lock) (*Lock, error) {
+	locks, err := ListLocks(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range locks {
+		if l.Target == lock.Target || !l.Covers(lock.Target) || l.Expired() || l.Owner == lock.Owner {
+			continue
+		}
+		if l.Acquired.Before(lock.Acquired) || (l.Acquired.Equal(lock.Acquired) && l.Target < lock.Target) {
+			return l, nil
+		}
+	}
+	return nil, nil
+}
+
+// Acquires a lock on {target} waiting up to {timeout} for locks held by others to be released
+func WaitForLock(c Marathon, target, owner, reason string, ttl, timeout time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		lock, err := AcquireLock(c, target, owner, reason, ttl)
+		if _, locked := err.(*LockedError); !locked || time.Now().After(deadline) {
+			return lock, err
+		}
+		log.Infof("%s - waiting...", err.Error())
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Releases the lock on {target} held by {owner}
+func ReleaseLock(c Marathon, target, owner string) error {
+	lock, err := GetLock(c, target)
+	if err != nil {
+		return err
+	}
+	if lock.Owner != owner {
+		return ErrorLockNotOwned
+	}
+	return BreakLock(c, target)
+}
+
+// Removes the lock on {target} regardless of its owner
+func BreakLock(c Marathon, target string) error {
+	_, err := c.DestroyApplication(lockAppID(target))
+	if err == httpclient.ErrorNotFound {
+		return ErrorLockNotHeld
+	}
+	return err
+}
+
+// Returns the lock held on {target}
+func GetLock(c Marathon, target string) (*Lock, error) {
+	app, err := c.GetApplication(lockAppID(target))
+	if err != nil {
+		if err == httpclient.ErrorNotFound {
+			return nil, ErrorLockNotHeld
+		}
+		return nil, err
+	}
+	return lockFromApplication(app), nil
+}
+
+// Returns all locks (including expired locks) ordered by target
+func ListLocks(c Marathon) ([]*Lock, error) {
+	locks := []*Lock{}
+	group, err := c.GetGroup(LockGroup)
+	if err != nil {
+		if err == httpclient.ErrorNotFound {
+			return locks, nil
+		}
+		return nil, err
+	}
+	for _, app := range group.Apps {
+		if _, ok := app.Labels[LabelLockTarget]; ok {
+			locks = append(locks, lockFromApplication(app))
+		}
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Target < locks[j].Target })
+	return locks, nil
+}
+
+// Escapes the characters of a target id which are also used to separate its segments within a lock id
+var lockIDEscaper = strings.NewReplacer("-", "--", ".", "-d", "/", ".")
+
+// The marker application id for a lock on {target}.  Hyphens are doubled, dots become '-d' and slashes
+// become dots so that every target maps to a distinct valid id.  eg. /product/api.v2 is stored as
+// /depcon-locks/product.api-dv2
+func lockAppID(target string) string {
+	return LockGroup + "/" + lockIDEscaper.Replace(utils.TrimRootPath(target))
+}
+
+// Returns true if {id} is within the LockGroup
+func isLockID(id string) bool {
+	lockGroup := utils.TrimRootPath(LockGroup)
+	id = utils.TrimRootPath(id)
+	return id == lockGroup || strings.HasPrefix(id, lockGroup+"/")
+}
+
+// The payload of a lock marker app.  Unlike Application, instances is always sent so the marker never runs
+type lockMarker struct {
+	ID        string            `json:"id"`
+	Cmd       string            `json:"cmd"`
+	CPUs      float64           `json:"cpus"`
+	Mem       float64           `json:"mem"`
+	Instances int               `json:"instances"`
+	Labels    map[string]string `json:"labels"`
+}
+
+func (c *MarathonClient) CreateLockMarker(lock *Lock) error {
+	log.Debugf("Creating lock marker for '%s'", lock.Target)
+	resp := c.http.HttpPost(c.marathonUrl(API_APPS), lock.marker(), new(Application))
+	if resp.Error != nil {
+		if resp.Error == httpclient.ErrorMessage {
+			if resp.Status == 409 {
+				return ErrorAppExists
+			}
+			return fmt.Errorf("Error occurred (Status %v) Body -> %s", resp.Status, resp.Content)
+		}
+		return resp.Error
+	}
+	return nil
+}
+
+func (l *Lock) marker() *lockMarker {
+	return &lockMarker{
+		ID:        lockAppID(l.Target),
+		Cmd:       "sleep 3600",
+		CPUs:      0.01,
+		Mem:       16,
+		Instances: 0,
+		Labels: map[string]string{
+			LabelLockTarget:   l.Target,
+			LabelLockOwner:    l.Owner,
+			LabelLockReason:   l.Reason,
+			LabelLockAcquired: l.Acquired.Format(time.RFC3339),
+			LabelLockExpires:  l.Expires.Format(time.RFC3339),
+		},
+	}
+}
+
+func lockFromApplication(app *Application) *Lock {
+	l := &Lock{
+		Target: app.Labels[LabelLockTarget],
+		Owner:  app.Labels[LabelLockOwner],
+		Reason: app.Labels[LabelLockReason],
+	}
+	l.Acquired, _ = time.Parse(time.RFC3339, app.Labels[LabelLockAcquired])
+	l.Expires, _ = time.Parse(time.RFC3339, app.Labels[LabelLockExpires])
+	return l
+}
@@ -0,0 +1,157 @@
+package marathon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ContainX/depcon/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+// An in memory client holding the lock marker apps.  {onCreate} is called after an app is created to
+// simulate concurrent changes by other users
+type lockClient struct {
+	Marathon
+	apps     map[string]*Application
+	onCreate func(c *lockClient)
+}
+
+func newLockClient(locks ...*Lock) *lockClient {
+	c := &lockClient{apps: map[string]*Application{}}
+	for _, l := range locks {
+		c.apps[lockAppID(l.Target)] = l.app()
+	}
+	return c
+}
+
+func (c *lockClient) GetGroup(id string) (*Group, error) {
+	g := &Group{GroupID: id}
+	for _, app := range c.apps {
+		g.Apps = append(g.Apps, app)
+	}
+	return g, nil
+}
+
+func (c *lockClient) GetApplication(id string) (*Application, error) {
+	if app, ok := c.apps[id]; ok {
+		return app, nil
+	}
+	return nil, httpclient.ErrorNotFound
+}
+
+func (c *lockClient) CreateLockMarker(lock *Lock) error {
+	app := lock.app()
+	if _, ok := c.apps[app.ID]; ok {
+		return ErrorAppExists
+	}
+	c.apps[app.ID] = app
+	if c.onCreate != nil {
+		c.onCreate(c)
+	}
+	return nil
+}
+
+// The marker app of {l} as reported by Marathon
+func (l *Lock) app() *Application {
+	m := l.marker()
+	return &Application{ID: m.ID, Cmd: m.Cmd, Labels: m.Labels}
+}
+
+func (c *lockClient) DestroyApplication(id string) (*DeploymentID, error) {
+	if _, ok := c.apps[id]; !ok {
+		return nil, httpclient.ErrorNotFound
+	}
+	delete(c.apps, id)
+	return &DeploymentID{}, nil
+}
+
+func TestLockCovers(t *testing.T) {
+	l := &Lock{Target: "/product/api"}
+
+	assert.True(t, l.Covers("/product/api"))
+	assert.True(t, l.Covers("product/api"))
+	assert.True(t, l.Covers("/product"))
+	assert.True(t, l.Covers("/product/api/worker"))
+	assert.False(t, l.Covers("/product/web"))
+	assert.False(t, l.Covers("/product/api2"))
+}
+
+func TestLockApplication(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	l := &Lock{Target: "/product/api", Owner: "jdoe@host", Reason: "release", Acquired: now, Expires: now.Add(time.Hour)}
+
+	app := l.app()
+	assert.Equal(t, "/depcon-locks/product.api", app.ID)
+	assert.Equal(t, 0, l.marker().Instances)
+	assert.NotEqual(t, lockAppID("/a/b.c"), lockAppID("/a.b/c"))
+	assert.NotEqual(t, lockAppID("/a-d/c"), lockAppID("/a.c"))
+	assert.Equal(t, "/depcon-locks/product--api.v--2-dbeta", lockAppID("/product-api/v-2.beta"))
+
+	parsed := lockFromApplication(app)
+	assert.Equal(t, l.Target, parsed.Target)
+	assert.Equal(t, l.Owner, parsed.Owner)
+	assert.Equal(t, l.Reason, parsed.Reason)
+	assert.True(t, l.Expires.Equal(parsed.Expires))
+	assert.False(t, parsed.Expired())
+}
+
+func TestAcquireLockWithGuardrails(t *testing.T) {
+	var posted map[string]interface{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == "/v2/apps" {
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &posted)
+			w.WriteHeader(201)
+			w.Write(body)
+			return
+		}
+		w.WriteHeader(404)
+	}))
+	defer s.Close()
+
+	c := NewMarathonClientWithOpts(s.URL, "", "", "", &MarathonOptions{Guardrails: &Guardrails{RequiredLabels: []string{"team"}}})
+	lock, err := AcquireLock(c, "/product/api", "jdoe@host", "release", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "/product/api", lock.Target)
+	assert.Equal(t, float64(0), posted["instances"], "instances must be sent so the marker never runs")
+
+	_, err = c.CreateApplication(&Application{ID: "/product/api", Cmd: "sleep 60"}, false, false)
+	assert.IsType(t, &GuardrailError{}, err)
+}
+
+func TestAcquireLockConcurrentParent(t *testing.T) {
+	c := newLockClient()
+	c.onCreate = func(c *lockClient) {
+		// another user locked the parent group between listing and creating
+		c.onCreate = nil
+		earlier := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+		c.apps[lockAppID("/product")] = (&Lock{Target: "/product", Owner: "other@host", Acquired: earlier, Expires: earlier.Add(time.Hour)}).app()
+	}
+
+	_, err := AcquireLock(c, "/product/api", "jdoe@host", "release", time.Hour)
+	assert.IsType(t, &LockedError{}, err)
+	assert.Equal(t, "/product", err.(*LockedError).Lock.Target)
+	_, held := c.apps[lockAppID("/product/api")]
+	assert.False(t, held, "the later lock must back off")
+}
+
+func TestAcquireLockExpiredReacquired(t *testing.T) {
+	past := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Second)
+	expired := &Lock{Target: "/product/api", Owner: "old@host", Acquired: past, Expires: past.Add(time.Hour)}
+	c := newLockClient(expired)
+
+	// the expired lock is listed but re-acquired by another user before it is broken
+	c.apps[lockAppID("/product/api")] = (&Lock{Target: "/product/api", Owner: "other@host", Acquired: time.Now(), Expires: time.Now().Add(time.Hour)}).app()
+	err := breakExpiredLock(c, expired)
+	assert.IsType(t, &LockedError{}, err)
+	assert.Equal(t, "other@host", c.apps[lockAppID("/product/api")].Labels[LabelLockOwner])
+
+	c = newLockClient(expired)
+	lock, err := AcquireLock(c, "/product/api", "jdoe@host", "release", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "jdoe@host", lock.Owner)
+}
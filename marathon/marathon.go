@@ -3,9 +3,11 @@ package marathon
 
 import (
 	"fmt"
+	"github.com/ContainX/depcon/pkg/encoding"
 	"github.com/ContainX/depcon/pkg/httpclient"
 	"github.com/ContainX/depcon/pkg/logger"
 	"github.com/ContainX/depcon/utils"
+	"io"
 	"sync"
 	"time"
 )
@@ -24,6 +26,7 @@ const (
 	API_INFO         = API_VERSION + "/info"
 	API_LEADER       = API_VERSION + "/leader"
 	API_EVENTS       = API_VERSION + "/events"
+	API_SUBSCRIPTION = API_VERSION + "/eventSubscriptions"
 	API_PING         = "ping"
 
 	DefaultTimeout = time.Duration(90) * time.Second
@@ -53,6 +56,24 @@ type CreateOptions struct {
 
 	// Do not actually create - output final parsed payload which would be POSTED and then exit
 	DryRun bool
+
+	// If set the metadata labels are stamped on every application before it is deployed
+	Metadata *DeployMetadata
+
+	// If set every parsed application or group must pass verification before it is deployed
+	Verifier Verifier
+
+	// If true descriptors declaring unknown fields are rejected and parse errors report their location
+	Strict bool
+
+	// The original descriptor used to locate parse errors in strict mode.  Defaults to the parsed file
+	Source *encoding.Source
+}
+
+// Verifies parsed applications and groups before they are deployed (eg. against deployment policies)
+type Verifier interface {
+	VerifyApplication(app *Application) error
+	VerifyGroup(group *Group) error
 }
 
 type Marathon interface {
@@ -81,10 +102,21 @@ type Marathon interface {
 	//         - if false and a application exists an error will be returned
 	CreateApplication(app *Application, wait, force bool) (*Application, error)
 
+	// Creates the marker application storing a deployment lock.  Returns ErrorAppExists if the
+	// target is already locked
+	// {lock} - the lock to store
+	CreateLockMarker(lock *Lock) error
+
 	// Responsible for parsing an application [ json | yaml ] and susbstituting variables.
 	// This method is called as part of the CreateApplicationFromFile method.
 	ParseApplicationFromFile(filename string, opts *CreateOptions) (*Application, error)
 
+	// Responsible for parsing an application [ json | yaml ] from a reader and substituting variables
+	// {r}    - the reader containing the application descriptor
+	// {et}   - the encoding type of the descriptor
+	// {opts} - create application options
+	ParseApplicationFromString(r io.Reader, et encoding.EncoderType, opts *CreateOptions) (*Application, error)
+
 	// Updates an Application
 	// {app} - the application structure containing configuration
 	// {wait} - if true will attempt to wait until the application updated is running
@@ -101,6 +133,11 @@ type Marathon interface {
 	// {id} - application identifier
 	GetApplication(id string) (*Application, error)
 
+	// Get an Application by Id including additional embedded resources
+	// {id}     - application identifier
+	// {embeds} - one or more embed options (eg. EmbedAppReadiness)
+	GetApplicationWithEmbed(id string, embeds ...string) (*Application, error)
+
 	// Determines if the application exists
 	// {id} - the application identifier
 	HasApplication(id string) (bool, error)
@@ -137,6 +174,17 @@ type Marathon interface {
 	// {timeout} - the max time to wait
 	WaitForApplicationHealthy(id string, timeout time.Duration) error
 
+	// Attempts to wait for an application to be running and ready (all readiness checks for all tasks passing)
+	// {id} - the application id
+	// {timeout} - the max time to wait
+	WaitForApplicationReady(id string, timeout time.Duration) error
+
+	// Attempts to wait for an application to be running and reach the states declared in the options
+	// {id} - the application id
+	// {timeout} - the max time to wait
+	// {opts} - wait options (healthy, ready)
+	WaitForApplicationWithOptions(id string, timeout time.Duration, opts *WaitOptions) error
+
 	/** Deployment API */
 
 	// Determines whether a deployment for the specified Id exists
@@ -183,6 +231,12 @@ type Marathon interface {
 	//         - if false and a group exists an error will be returned
 	CreateGroup(group *Group, wait, force bool) (*Group, error)
 
+	// Responsible for parsing a group [ json | yaml ] from a reader and substituting variables
+	// {r}    - the reader containing the group descriptor
+	// {et}   - the encoding type of the descriptor
+	// {opts} - create application options
+	ParseGroupFromString(r io.Reader, et encoding.EncoderType, opts *CreateOptions) (*Group, error)
+
 	// List all groups
 	ListGroups() (*Groups, error)
 
@@ -221,6 +275,15 @@ type Marathon interface {
 	// List Queue - tasks currently pending
 	ListQueue() (*Queue, error)
 
+	// Get the launch queue entry for an application including the last unused offers and
+	// offer decline statistics.  Returns nil if the application is not queued
+	// {id} - the application identifier
+	GetQueuedApplication(id string) (*QueuedTask, error)
+
+	// Resets the launch delay (backoff) of an application so tasks are launched immediately
+	// {id} - the application identifier
+	ResetQueueDelay(id string) error
+
 	/** Event API */
 
 	// Creates an event stream listener which will filter based on the specified
@@ -230,6 +293,17 @@ type Marathon interface {
 	// Removes the channel from the event stream listener
 	CloseEventStreamListener(channel EventsChannel)
 
+	// List the HTTP callback URLs which are subscribed to the event bus
+	ListEventSubscriptions() (*EventSubscriptions, error)
+
+	// Registers an HTTP callback URL as an event subscriber
+	// {callbackUrl} - the URL which will receive events
+	AddEventSubscription(callbackUrl string) (*EventSubscriptionChange, error)
+
+	// Unregisters an HTTP callback URL from the event subscribers
+	// {callbackUrl} - the URL which was previously subscribed
+	RemoveEventSubscription(callbackUrl string) (*EventSubscriptionChange, error)
+
 	/** Marathon Server Info API */
 
 	// Pings the Marathon host via the /ping endpoint
@@ -238,6 +312,10 @@ type Marathon interface {
 	// Get info about the Marathon Instance
 	GetMarathonInfo() (*MarathonInfo, error)
 
+	// Get the capability set of the Marathon server based on its version.  The version is
+	// fetched once and cached for the lifetime of the client
+	Capabilities() (*Capabilities, error)
+
 	// Get the current Marathon leader
 	GetCurrentLeader() (*LeaderInfo, error)
 
@@ -251,6 +329,7 @@ type MarathonClient struct {
 	hosts            []string
 	opts             *MarathonOptions
 	eventStreamState *EventStreamState
+	capabilities     *Capabilities
 }
 
 type MarathonHAClient struct {
@@ -266,6 +345,10 @@ type MarathonOptions struct {
 	WaitTimeout      time.Duration
 	TLSAllowInsecure bool
 	DeploymentChan   chan DeploymentStatus
+	// if true descriptors using features the server does not support are rejected, otherwise a warning is logged
+	FailOnUnsupported bool
+	// if set applications and groups are verified against the guardrails before they are created, updated or scaled
+	Guardrails *Guardrails
 }
 
 type DeploymentStatus struct {
@@ -325,6 +408,17 @@ func initCreateOptions(opts *CreateOptions) *CreateOptions {
 	return opts
 }
 
+// Returns {opts} identifying {filename} as the source of strict parsing errors unless a source is
+// already specified
+func withSource(opts *CreateOptions, filename string) *CreateOptions {
+	if opts == nil || !opts.Strict || opts.Source != nil {
+		return opts
+	}
+	o := *opts
+	o.Source = &encoding.Source{Filename: filename}
+	return &o
+}
+
 func (c *MarathonClient) logOutput(f func(message string, args ...interface{}), message string, args ...interface{}) {
 	m := fmt.Sprintf(message, args...)
 	f(m)
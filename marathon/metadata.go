@@ -0,0 +1,135 @@
+package marathon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	// Prefix of the labels depcon stamps on deployed applications
+	MetadataLabelPrefix = "depcon."
+
+	LabelDeployedBy = MetadataLabelPrefix + "deployed-by"
+	LabelHost       = MetadataLabelPrefix + "host"
+	LabelVersion    = MetadataLabelPrefix + "version"
+	LabelGitCommit  = MetadataLabelPrefix + "git-commit"
+	LabelGitBranch  = MetadataLabelPrefix + "git-branch"
+	LabelChecksum   = MetadataLabelPrefix + "checksum"
+	LabelDeployedAt = MetadataLabelPrefix + "deployed-at"
+	LabelRelease    = MetadataLabelPrefix + "release"
+)
+
+// Describes who deployed an application, from where and what.  Empty values are not stamped
+type DeployMetadata struct {
+	User      string
+	Host      string
+	Version   string
+	GitCommit string
+	GitBranch string
+	// The checksum of the descriptor
+	Checksum string
+	// If true and no Checksum has been specified the checksum of each application's definition is used
+	ComputeChecksum bool
+	// RFC3339 timestamp of the deployment
+	Timestamp string
+	// The name and version of the bundle the descriptor was deployed from (eg. product@1.4.0)
+	Release string
+}
+
+// Returns the metadata as labels omitting empty values
+func (m *DeployMetadata) Labels() map[string]string {
+	labels := map[string]string{}
+	for k, v := range map[string]string{
+		LabelDeployedBy: m.User,
+		LabelHost:       m.Host,
+		LabelVersion:    m.Version,
+		LabelGitCommit:  m.GitCommit,
+		LabelGitBranch:  m.GitBranch,
+		LabelChecksum:   m.Checksum,
+		LabelDeployedAt: m.Timestamp,
+		LabelRelease:    m.Release,
+	} {
+		if v != "" {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// Returns the sha256 (hex) of the application definition excluding runtime state and metadata labels.
+// Only changes to the definition itself change the checksum
+func (app *Application) Checksum() string {
+	data, err := json.Marshal(app.Descriptor())
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Adds the {metadata} labels to the application replacing any previously stamped metadata
+func (app *Application) StampMetadata(metadata *DeployMetadata) {
+	if metadata == nil {
+		return
+	}
+	if metadata.Checksum == "" && metadata.ComputeChecksum {
+		m := *metadata
+		m.Checksum = app.Checksum()
+		metadata = &m
+	}
+	labels := map[string]string{}
+	for k, v := range app.Labels {
+		if !IsMetadataLabel(k) {
+			labels[k] = v
+		}
+	}
+	for k, v := range metadata.Labels() {
+		labels[k] = v
+	}
+	app.Labels = labels
+}
+
+// Stamps the {metadata} labels on every application within the group and its sub groups
+func (g *Group) StampMetadata(metadata *DeployMetadata) {
+	for _, app := range g.Apps {
+		app.StampMetadata(metadata)
+	}
+	for _, sg := range g.Groups {
+		sg.StampMetadata(metadata)
+	}
+}
+
+// Returns the deployment metadata labels of the application
+func (app *Application) MetadataLabels() map[string]string {
+	labels := map[string]string{}
+	for k, v := range app.Labels {
+		if IsMetadataLabel(k) {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// Returns the labels of the application excluding the deployment metadata labels
+func (app *Application) UserLabels() map[string]string {
+	return removeMetadataLabels(app.Labels)
+}
+
+func IsMetadataLabel(key string) bool {
+	return strings.HasPrefix(key, MetadataLabelPrefix)
+}
+
+func removeMetadataLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if !IsMetadataLabel(k) {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package marathon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseApplicationStampsMetadata(t *testing.T) {
+	c := NewMarathonClient("http://localhost:8080", "", "", "")
+	descriptor := `{"id": "/product/api", "labels": {"team": "core", "depcon.git-commit": "old"}}`
+	opts := &CreateOptions{Metadata: &DeployMetadata{User: "jdoe", GitBranch: "master", ComputeChecksum: true}}
+
+	app, err := c.ParseApplicationFromString(strings.NewReader(descriptor), encoding.JSON, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "core"}, app.UserLabels())
+
+	metadata := app.MetadataLabels()
+	assert.Equal(t, 3, len(metadata))
+	assert.Equal(t, "jdoe", metadata[LabelDeployedBy])
+	assert.Equal(t, "master", metadata[LabelGitBranch])
+	assert.Equal(t, 64, len(metadata[LabelChecksum]))
+	assert.Equal(t, "", opts.Metadata.Checksum, "options should not be modified")
+}
+
+func TestMetadataLabelsIgnored(t *testing.T) {
+	live := &Application{ID: "/api", Version: "1", Labels: map[string]string{"team": "core", LabelDeployedBy: "jdoe"}}
+	desired := &Application{ID: "/api", Labels: map[string]string{"team": "core"}}
+	desired.StampMetadata(&DeployMetadata{User: "asmith"})
+
+	changes, err := Diff(desired, live)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	assert.Equal(t, map[string]string{"team": "core"}, live.Descriptor().Labels)
+}
+
+func TestGroupMetadataChecksumPerApp(t *testing.T) {
+	g := &Group{GroupID: "/product", Apps: []*Application{{ID: "api", Instances: 2}, {ID: "worker", Instances: 1}}}
+	g.StampMetadata(&DeployMetadata{ComputeChecksum: true})
+	api, worker := g.Apps[0].MetadataLabels()[LabelChecksum], g.Apps[1].MetadataLabels()[LabelChecksum]
+	assert.NotEqual(t, api, worker)
+
+	changed := &Group{GroupID: "/product", Apps: []*Application{{ID: "api", Instances: 2}, {ID: "worker", Instances: 3}}}
+	changed.StampMetadata(&DeployMetadata{ComputeChecksum: true})
+	assert.Equal(t, api, changed.Apps[0].MetadataLabels()[LabelChecksum], "unchanged apps keep their checksum")
+	assert.NotEqual(t, worker, changed.Apps[1].MetadataLabels()[LabelChecksum])
+
+	g.StampMetadata(&DeployMetadata{ComputeChecksum: true})
+	assert.Equal(t, api, g.Apps[0].MetadataLabels()[LabelChecksum], "restamping does not change the checksum")
+}
@@ -0,0 +1,480 @@
+package marathon
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Decodes {data} into {v} which must be a pointer to a type without custom JSON marshalling.  Any
+// top level fields within {data} which are not declared by {v} are returned so they can be passed
+// through to Marathon when the type is marshalled again
+func decodeWithPassthrough(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		// not an object (eg. null) - nothing to pass through
+		return nil, nil
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v))
+	for k := range raw {
+		if known[strings.ToLower(k)] {
+			delete(raw, k)
+		}
+	}
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return raw, nil
+}
+
+// Encodes {v} which must be a type without custom JSON marshalling and appends the {unknown}
+// fields which were captured during decoding
+func encodeWithPassthrough(v interface{}, unknown map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(unknown) == 0 {
+		return b, err
+	}
+
+	keys := make([]string, 0, len(unknown))
+	for k := range unknown {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(b[:len(b)-1])
+	hasFields := len(bytes.TrimSpace(b)) > 2
+
+	for _, k := range keys {
+		if hasFields {
+			buf.WriteByte(',')
+		}
+		kb, _ := json.Marshal(k)
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(unknown[k])
+		hasFields = true
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Returns the lower cased JSON field names declared by the struct type {t}
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		names[strings.ToLower(name)] = true
+	}
+	return names
+}
+
+type application Application
+
+func (app *Application) UnmarshalJSON(data []byte) error {
+	a := new(application)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*app = Application(*a)
+	app.Unknown = unknown
+	return nil
+}
+
+func (app Application) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(application(app), app.Unknown)
+}
+
+type group Group
+
+func (g *Group) UnmarshalJSON(data []byte) error {
+	a := new(group)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*g = Group(*a)
+	g.Unknown = unknown
+	return nil
+}
+
+func (g Group) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(group(g), g.Unknown)
+}
+
+type container Container
+
+func (c *Container) UnmarshalJSON(data []byte) error {
+	a := new(container)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*c = Container(*a)
+	c.Unknown = unknown
+	return nil
+}
+
+func (c Container) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(container(c), c.Unknown)
+}
+
+type docker Docker
+
+func (d *Docker) UnmarshalJSON(data []byte) error {
+	a := new(docker)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*d = Docker(*a)
+	d.Unknown = unknown
+	return nil
+}
+
+func (d Docker) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(docker(d), d.Unknown)
+}
+
+type healthCheck HealthCheck
+
+func (h *HealthCheck) UnmarshalJSON(data []byte) error {
+	a := new(healthCheck)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*h = HealthCheck(*a)
+	h.Unknown = unknown
+	return nil
+}
+
+func (h HealthCheck) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(healthCheck(h), h.Unknown)
+}
+
+type appc Appc
+
+func (c *Appc) UnmarshalJSON(data []byte) error {
+	a := new(appc)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*c = Appc(*a)
+	c.Unknown = unknown
+	return nil
+}
+
+func (c Appc) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(appc(c), c.Unknown)
+}
+
+type network Network
+
+func (n *Network) UnmarshalJSON(data []byte) error {
+	a := new(network)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*n = Network(*a)
+	n.Unknown = unknown
+	return nil
+}
+
+func (n Network) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(network(n), n.Unknown)
+}
+
+type secret Secret
+
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	a := new(secret)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*s = Secret(*a)
+	s.Unknown = unknown
+	return nil
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(secret(s), s.Unknown)
+}
+
+type portDefinition PortDefinition
+
+func (p *PortDefinition) UnmarshalJSON(data []byte) error {
+	a := new(portDefinition)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*p = PortDefinition(*a)
+	p.Unknown = unknown
+	return nil
+}
+
+func (p PortDefinition) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(portDefinition(p), p.Unknown)
+}
+
+type fetch Fetch
+
+func (f *Fetch) UnmarshalJSON(data []byte) error {
+	a := new(fetch)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*f = Fetch(*a)
+	f.Unknown = unknown
+	return nil
+}
+
+func (f Fetch) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(fetch(f), f.Unknown)
+}
+
+type portMapping PortMapping
+
+func (p *PortMapping) UnmarshalJSON(data []byte) error {
+	a := new(portMapping)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*p = PortMapping(*a)
+	p.Unknown = unknown
+	return nil
+}
+
+func (p PortMapping) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(portMapping(p), p.Unknown)
+}
+
+type parameters Parameters
+
+func (p *Parameters) UnmarshalJSON(data []byte) error {
+	a := new(parameters)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*p = Parameters(*a)
+	p.Unknown = unknown
+	return nil
+}
+
+func (p Parameters) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(parameters(p), p.Unknown)
+}
+
+type volume Volume
+
+func (v *Volume) UnmarshalJSON(data []byte) error {
+	a := new(volume)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*v = Volume(*a)
+	v.Unknown = unknown
+	return nil
+}
+
+func (v Volume) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(volume(v), v.Unknown)
+}
+
+type persistentVolume PersistentVolume
+
+func (v *PersistentVolume) UnmarshalJSON(data []byte) error {
+	a := new(persistentVolume)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*v = PersistentVolume(*a)
+	v.Unknown = unknown
+	return nil
+}
+
+func (v PersistentVolume) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(persistentVolume(v), v.Unknown)
+}
+
+type externalVolume ExternalVolume
+
+func (v *ExternalVolume) UnmarshalJSON(data []byte) error {
+	a := new(externalVolume)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*v = ExternalVolume(*a)
+	v.Unknown = unknown
+	return nil
+}
+
+func (v ExternalVolume) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(externalVolume(v), v.Unknown)
+}
+
+type pullConfig PullConfig
+
+func (p *PullConfig) UnmarshalJSON(data []byte) error {
+	a := new(pullConfig)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*p = PullConfig(*a)
+	p.Unknown = unknown
+	return nil
+}
+
+func (p PullConfig) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(pullConfig(p), p.Unknown)
+}
+
+type upgradeStrategy UpgradeStrategy
+
+func (u *UpgradeStrategy) UnmarshalJSON(data []byte) error {
+	a := new(upgradeStrategy)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*u = UpgradeStrategy(*a)
+	u.Unknown = unknown
+	return nil
+}
+
+func (u UpgradeStrategy) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(upgradeStrategy(u), u.Unknown)
+}
+
+type healthCheckCommand HealthCheckCommand
+
+func (h *HealthCheckCommand) UnmarshalJSON(data []byte) error {
+	a := new(healthCheckCommand)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*h = HealthCheckCommand(*a)
+	h.Unknown = unknown
+	return nil
+}
+
+func (h HealthCheckCommand) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(healthCheckCommand(h), h.Unknown)
+}
+
+type taskIPAddress TaskIPAddress
+
+func (t *TaskIPAddress) UnmarshalJSON(data []byte) error {
+	a := new(taskIPAddress)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*t = TaskIPAddress(*a)
+	t.Unknown = unknown
+	return nil
+}
+
+func (t TaskIPAddress) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(taskIPAddress(t), t.Unknown)
+}
+
+type discovery Discovery
+
+func (d *Discovery) UnmarshalJSON(data []byte) error {
+	a := new(discovery)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*d = Discovery(*a)
+	d.Unknown = unknown
+	return nil
+}
+
+func (d Discovery) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(discovery(d), d.Unknown)
+}
+
+type discoveryPorts DiscoveryPorts
+
+func (d *DiscoveryPorts) UnmarshalJSON(data []byte) error {
+	a := new(discoveryPorts)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*d = DiscoveryPorts(*a)
+	d.Unknown = unknown
+	return nil
+}
+
+func (d DiscoveryPorts) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(discoveryPorts(d), d.Unknown)
+}
+
+type readinessCheck ReadinessCheck
+
+func (r *ReadinessCheck) UnmarshalJSON(data []byte) error {
+	a := new(readinessCheck)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*r = ReadinessCheck(*a)
+	r.Unknown = unknown
+	return nil
+}
+
+func (r ReadinessCheck) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(readinessCheck(r), r.Unknown)
+}
+
+type residency Residency
+
+func (r *Residency) UnmarshalJSON(data []byte) error {
+	a := new(residency)
+	unknown, err := decodeWithPassthrough(data, a)
+	if err != nil {
+		return err
+	}
+	*r = Residency(*a)
+	r.Unknown = unknown
+	return nil
+}
+
+func (r Residency) MarshalJSON() ([]byte, error) {
+	return encodeWithPassthrough(residency(r), r.Unknown)
+}
@@ -0,0 +1,146 @@
+package marathon
+
+// A Marathon pod descriptor (Marathon 1.4+).  Pods are not deployed by depcon, the types describe pod
+// descriptors so their schema can be exported for editors
+type Pod struct {
+	ID                string            `json:"id"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Version           string            `json:"version,omitempty"`
+	User              string            `json:"user,omitempty"`
+	Environment       map[string]EnvVar `json:"environment,omitempty"`
+	Containers        []*PodContainer   `json:"containers"`
+	Secrets           map[string]Secret `json:"secrets,omitempty"`
+	Volumes           []*PodVolume      `json:"volumes,omitempty"`
+	Networks          []*Network        `json:"networks,omitempty"`
+	Scaling           *PodScaling       `json:"scaling,omitempty"`
+	Scheduling        *PodScheduling    `json:"scheduling,omitempty"`
+	ExecutorResources *PodResources     `json:"executorResources,omitempty"`
+	Dependencies      []string          `json:"dependencies,omitempty"`
+}
+
+type PodContainer struct {
+	Name         string            `json:"name"`
+	Exec         *PodExec          `json:"exec,omitempty"`
+	Resources    *PodResources     `json:"resources"`
+	Endpoints    []*PodEndpoint    `json:"endpoints,omitempty"`
+	Image        *PodImage         `json:"image,omitempty"`
+	Environment  map[string]EnvVar `json:"environment,omitempty"`
+	User         string            `json:"user,omitempty"`
+	HealthCheck  *PodHealthCheck   `json:"healthCheck,omitempty"`
+	VolumeMounts []*PodVolumeMount `json:"volumeMounts,omitempty"`
+	Artifacts    []*PodArtifact    `json:"artifacts,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Lifecycle    *PodLifecycle     `json:"lifecycle,omitempty"`
+}
+
+type PodExec struct {
+	Command            *PodCommand `json:"command"`
+	OverrideEntrypoint bool        `json:"overrideEntrypoint,omitempty"`
+}
+
+type PodCommand struct {
+	Shell string   `json:"shell,omitempty"`
+	Argv  []string `json:"argv,omitempty"`
+}
+
+type PodResources struct {
+	CPUs float64 `json:"cpus"`
+	Mem  float64 `json:"mem"`
+	Disk float64 `json:"disk,omitempty"`
+	GPUs int     `json:"gpus,omitempty"`
+}
+
+type PodEndpoint struct {
+	Name          string            `json:"name"`
+	ContainerPort int               `json:"containerPort,omitempty"`
+	HostPort      int               `json:"hostPort,omitempty"`
+	Protocol      []string          `json:"protocol,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+}
+
+type PodImage struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	ForcePull bool   `json:"forcePull,omitempty"`
+}
+
+type PodHealthCheck struct {
+	HTTP                   *PodHTTPHealthCheck `json:"http,omitempty"`
+	TCP                    *PodTCPHealthCheck  `json:"tcp,omitempty"`
+	Exec                   *PodExec            `json:"exec,omitempty"`
+	GracePeriodSeconds     int                 `json:"gracePeriodSeconds,omitempty"`
+	IntervalSeconds        int                 `json:"intervalSeconds,omitempty"`
+	MaxConsecutiveFailures int                 `json:"maxConsecutiveFailures,omitempty"`
+	TimeoutSeconds         int                 `json:"timeoutSeconds,omitempty"`
+	DelaySeconds           int                 `json:"delaySeconds,omitempty"`
+}
+
+type PodHTTPHealthCheck struct {
+	Endpoint string `json:"endpoint"`
+	Path     string `json:"path,omitempty"`
+	Scheme   string `json:"scheme,omitempty"`
+}
+
+type PodTCPHealthCheck struct {
+	Endpoint string `json:"endpoint"`
+}
+
+type PodVolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+type PodArtifact struct {
+	URI        string `json:"uri"`
+	Extract    bool   `json:"extract,omitempty"`
+	Executable bool   `json:"executable,omitempty"`
+	Cache      bool   `json:"cache,omitempty"`
+	DestPath   string `json:"destPath,omitempty"`
+}
+
+type PodLifecycle struct {
+	KillGracePeriodSeconds float64 `json:"killGracePeriodSeconds,omitempty"`
+}
+
+type PodVolume struct {
+	Name       string               `json:"name"`
+	Host       string               `json:"host,omitempty"`
+	Persistent *PodPersistentVolume `json:"persistent,omitempty"`
+}
+
+type PodPersistentVolume struct {
+	Type string `json:"type,omitempty"`
+	Size int    `json:"size"`
+}
+
+type PodScaling struct {
+	Kind         string `json:"kind,omitempty"`
+	Instances    int    `json:"instances,omitempty"`
+	MaxInstances int    `json:"maxInstances,omitempty"`
+}
+
+type PodScheduling struct {
+	Backoff             *PodBackoff          `json:"backoff,omitempty"`
+	Upgrade             *UpgradeStrategy     `json:"upgrade,omitempty"`
+	Placement           *PodPlacement        `json:"placement,omitempty"`
+	KillSelection       string               `json:"killSelection,omitempty"`
+	UnreachableStrategy *UnreachableStrategy `json:"unreachableStrategy,omitempty"`
+}
+
+type PodBackoff struct {
+	Backoff        float64 `json:"backoff,omitempty"`
+	BackoffFactor  float64 `json:"backoffFactor,omitempty"`
+	MaxLaunchDelay float64 `json:"maxLaunchDelay,omitempty"`
+}
+
+type PodPlacement struct {
+	Constraints           []*PodConstraint `json:"constraints,omitempty"`
+	AcceptedResourceRoles []string         `json:"acceptedResourceRoles,omitempty"`
+}
+
+type PodConstraint struct {
+	FieldName string `json:"fieldName"`
+	Operator  string `json:"operator"`
+	Value     string `json:"value,omitempty"`
+}
@@ -0,0 +1,33 @@
+package marathon
+
+import (
+	"testing"
+
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodSchema(t *testing.T) {
+	descriptor := `
+id: /product/pod
+scaling: { kind: fixed, instances: 2 }
+containers:
+  - name: api
+    resources: { cpus: 0.5, mem: 256 }
+    image: { kind: DOCKER, id: "api:1.0" }
+    endpoints: [ { name: http, containerPort: 80, protocol: [ tcp ] } ]
+    healthCheck: { http: { endpoint: http, path: /health } }
+networks: [ { mode: container/bridge } ]
+`
+	schema := PodSchema()
+	doc, err := encoding.Decode(encoding.YAML, descriptor)
+	assert.NoError(t, err)
+	assert.Empty(t, schema.Validate(doc))
+	assert.True(t, schema.Definitions["Pod"].Properties["version"].ReadOnly)
+
+	doc, err = encoding.Decode(encoding.YAML, "id: /product/pod\ncontainers: [ { name: api, image: { kind: RKT, id: api } } ]\n")
+	assert.NoError(t, err)
+	violations := schema.Validate(doc)
+	assert.Len(t, violations, 1)
+	assert.Contains(t, violations[0].Error(), "'RKT' must be one of DOCKER, APPC")
+}
@@ -0,0 +1,438 @@
+// Deployment policies authored as assertions against the fields of applications and groups
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/ContainX/depcon/pkg/logger"
+)
+
+const (
+	ActionDeny = "deny"
+	ActionWarn = "warn"
+
+	KindApp   = "app"
+	KindGroup = "group"
+
+	OpExists   = "exists"
+	OpAbsent   = "absent"
+	OpEq       = "eq"
+	OpNe       = "ne"
+	OpLt       = "lt"
+	OpLte      = "lte"
+	OpGt       = "gt"
+	OpGte      = "gte"
+	OpIn       = "in"
+	OpNotIn    = "notIn"
+	OpMatches  = "matches"
+	OpContains = "contains"
+)
+
+var (
+	operators  = []string{OpExists, OpAbsent, OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpIn, OpNotIn, OpMatches, OpContains}
+	pathRegex  = regexp.MustCompile(`^([^.\[\]]+)((\[(\d+|\*)\])*)$`)
+	indexRegex = regexp.MustCompile(`\[(\d+|\*)\]`)
+	log        = logger.GetLogger("depcon.marathon.policy")
+)
+
+// A set of policies loaded from one or more policy files
+type PolicySet struct {
+	Policies []*Policy `json:"policies"`
+}
+
+// A policy is violated when its {When} conditions hold (or none are declared) and any of its {Assert}
+// assertions do not
+type Policy struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// deny (default) or warn
+	Action string `json:"action,omitempty"`
+	// app (default) or group
+	Kind   string       `json:"kind,omitempty"`
+	When   []*Assertion `json:"when,omitempty"`
+	Assert []*Assertion `json:"assert"`
+	// Reported when the policy is violated.  Defaults to the description
+	Message string `json:"message,omitempty"`
+}
+
+// Compares the value(s) at {Path} using {Op}.  Paths are dotted field names as they appear within the
+// descriptor with optional indexes (eg. container.docker.portMappings[*].name).  A [*] index requires
+// every element to satisfy the assertion and holds for a missing array.  Missing fields only satisfy the
+// exists, absent, ne and notIn operators.  eg. instances (omitted when 0 or left to Marathon's default of
+// 1) never satisfies gte.  Use a 'when' condition to assert against optional fields only when present
+type Assertion struct {
+	Path  string      `json:"path"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// A policy which was violated by an application or group
+type Violation struct {
+	File    string `json:"file,omitempty"`
+	ID      string `json:"id"`
+	Policy  string `json:"policy"`
+	Action  string `json:"action"`
+	Message string `json:"message"`
+}
+
+// Returned when one or more deny policies are violated
+type PolicyError struct {
+	Violations []*Violation
+}
+
+func (e *PolicyError) Error() string {
+	lines := []string{}
+	for _, v := range e.Violations {
+		lines = append(lines, fmt.Sprintf("%s: [%s] %s", v.ID, v.Policy, v.Message))
+	}
+	return "Deployment denied by policy:\n  - " + strings.Join(lines, "\n  - ")
+}
+
+// Loads and merges the policies within {files} (json or yaml) resolving relative paths from the current
+// directory
+func Load(files ...string) (*PolicySet, error) {
+	return LoadFrom("", files...)
+}
+
+// Loads and merges the policies within {files} (json or yaml) resolving relative paths from {dir}
+// (eg. the directory of the configuration declaring them)
+func LoadFrom(dir string, files ...string) (*PolicySet, error) {
+	ps := &PolicySet{Policies: []*Policy{}}
+	for _, f := range files {
+		if dir != "" && !filepath.IsAbs(f) {
+			f = filepath.Join(dir, f)
+		}
+		encoder, err := encoding.NewEncoderFromFileExt(f)
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		set := &PolicySet{}
+		if err := encoder.UnMarshalStr(string(data), set); err != nil {
+			return nil, fmt.Errorf("%s: %s", f, err.Error())
+		}
+		if err := set.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %s", f, err.Error())
+		}
+		ps.Policies = append(ps.Policies, set.Policies...)
+	}
+	return ps, nil
+}
+
+// Verifies every policy declares a name, valid action, kind, paths and operators
+func (ps *PolicySet) Validate() error {
+	for i, p := range ps.Policies {
+		if p.Name == "" {
+			return fmt.Errorf("policy %d has no name", i)
+		}
+		if p.Action != "" && p.Action != ActionDeny && p.Action != ActionWarn {
+			return fmt.Errorf("policy '%s': invalid action '%s', must be [deny | warn]", p.Name, p.Action)
+		}
+		if p.Kind != "" && p.Kind != KindApp && p.Kind != KindGroup {
+			return fmt.Errorf("policy '%s': invalid kind '%s', must be [app | group]", p.Name, p.Kind)
+		}
+		if len(p.Assert) == 0 {
+			return fmt.Errorf("policy '%s' has no assertions", p.Name)
+		}
+		for _, a := range append(append([]*Assertion{}, p.When...), p.Assert...) {
+			if err := a.validate(); err != nil {
+				return fmt.Errorf("policy '%s': %s", p.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Assertion) validate() error {
+	found := false
+	for _, op := range operators {
+		found = found || op == a.Op
+	}
+	if !found {
+		return fmt.Errorf("invalid operator '%s', must be one of %s", a.Op, strings.Join(operators, ", "))
+	}
+	for _, part := range strings.Split(a.Path, ".") {
+		if !pathRegex.MatchString(part) {
+			return fmt.Errorf("invalid path '%s'", a.Path)
+		}
+	}
+	if a.Op == OpMatches {
+		if _, err := regexp.Compile(fmt.Sprint(a.Value)); err != nil {
+			return fmt.Errorf("invalid pattern '%v': %s", a.Value, err.Error())
+		}
+	}
+	return nil
+}
+
+// Returns the policies violated by {app}
+func (ps *PolicySet) EvaluateApplication(app *marathon.Application) ([]*Violation, error) {
+	return ps.evaluate(KindApp, app.ID, app)
+}
+
+// Returns the policies violated by {group} and every application within it
+func (ps *PolicySet) EvaluateGroup(group *marathon.Group) ([]*Violation, error) {
+	violations, err := ps.evaluate(KindGroup, group.GroupID, group)
+	if err != nil {
+		return nil, err
+	}
+	for _, app := range group.FlattenApps() {
+		v, err := ps.EvaluateApplication(app)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, v...)
+	}
+	return violations, nil
+}
+
+// Implements marathon.Verifier.  Warnings are logged and deny violations are returned as a *PolicyError
+func (ps *PolicySet) VerifyApplication(app *marathon.Application) error {
+	return verify(ps.EvaluateApplication(app))
+}
+
+// Implements marathon.Verifier.  Warnings are logged and deny violations are returned as a *PolicyError
+func (ps *PolicySet) VerifyGroup(group *marathon.Group) error {
+	return verify(ps.EvaluateGroup(group))
+}
+
+// Returns true if any of the {violations} denies the deployment
+func Denied(violations []*Violation) bool {
+	for _, v := range violations {
+		if v.Action == ActionDeny {
+			return true
+		}
+	}
+	return false
+}
+
+func verify(violations []*Violation, err error) error {
+	if err != nil {
+		return err
+	}
+	denied := []*Violation{}
+	for _, v := range violations {
+		if v.Action == ActionWarn {
+			log.Warningf("%s: [%s] %s", v.ID, v.Policy, v.Message)
+			continue
+		}
+		denied = append(denied, v)
+	}
+	if len(denied) > 0 {
+		return &PolicyError{Violations: denied}
+	}
+	return nil
+}
+
+func (ps *PolicySet) evaluate(kind, id string, v interface{}) ([]*Violation, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	violations := []*Violation{}
+	for _, p := range ps.Policies {
+		if p.kind() != kind || !allHold(p.When, doc) || allHold(p.Assert, doc) {
+			continue
+		}
+		violations = append(violations, &Violation{ID: id, Policy: p.Name, Action: p.action(), Message: p.message()})
+	}
+	return violations, nil
+}
+
+func (p *Policy) kind() string {
+	if p.Kind == "" {
+		return KindApp
+	}
+	return p.Kind
+}
+
+func (p *Policy) action() string {
+	if p.Action == "" {
+		return ActionDeny
+	}
+	return p.Action
+}
+
+func (p *Policy) message() string {
+	if p.Message != "" {
+		return p.Message
+	}
+	if p.Description != "" {
+		return p.Description
+	}
+	return "policy violated"
+}
+
+func allHold(assertions []*Assertion, doc interface{}) bool {
+	for _, a := range assertions {
+		if !a.holds(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if the assertion holds for every value at its path within {doc}
+func (a *Assertion) holds(doc interface{}) bool {
+	values := resolve(doc, strings.Split(a.Path, "."))
+	present := 0
+	for _, v := range values {
+		if _, ok := v.(missing); !ok {
+			present++
+		}
+	}
+	switch a.Op {
+	case OpExists:
+		return present > 0 && present == len(values)
+	case OpAbsent:
+		return present == 0
+	}
+	for _, v := range values {
+		if _, ok := v.(missing); ok {
+			if a.Op != OpNe && a.Op != OpNotIn {
+				return false
+			}
+			continue
+		}
+		if !a.compare(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Assertion) compare(v interface{}) bool {
+	switch a.Op {
+	case OpEq:
+		return equal(v, a.Value)
+	case OpNe:
+		return !equal(v, a.Value)
+	case OpLt, OpLte, OpGt, OpGte:
+		x, ok1 := number(v)
+		y, ok2 := number(a.Value)
+		if !ok1 || !ok2 {
+			return false
+		}
+		switch a.Op {
+		case OpLt:
+			return x < y
+		case OpLte:
+			return x <= y
+		case OpGt:
+			return x > y
+		}
+		return x >= y
+	case OpIn, OpNotIn:
+		in := false
+		if list, ok := a.Value.([]interface{}); ok {
+			for _, item := range list {
+				in = in || equal(v, item)
+			}
+		}
+		return in == (a.Op == OpIn)
+	case OpMatches:
+		s, ok := v.(string)
+		return ok && regexp.MustCompile(fmt.Sprint(a.Value)).MatchString(s)
+	case OpContains:
+		switch t := v.(type) {
+		case string:
+			return strings.Contains(t, fmt.Sprint(a.Value))
+		case []interface{}:
+			for _, item := range t {
+				if equal(item, a.Value) {
+					return true
+				}
+			}
+		case map[string]interface{}:
+			_, ok := t[fmt.Sprint(a.Value)]
+			return ok
+		}
+	}
+	return false
+}
+
+// Placeholder for a value which is not present within the document
+type missing struct{}
+
+// Resolves the values at {path} within {doc}.  Values which could not be found are returned as missing{}
+func resolve(doc interface{}, path []string) []interface{} {
+	if doc == nil {
+		return []interface{}{missing{}}
+	}
+	if len(path) == 0 {
+		return []interface{}{doc}
+	}
+	m := pathRegex.FindStringSubmatch(path[0])
+	obj, ok := doc.(map[string]interface{})
+	if m == nil || !ok {
+		return []interface{}{missing{}}
+	}
+
+	values := []interface{}{obj[m[1]]}
+	for _, idx := range indexRegex.FindAllStringSubmatch(m[2], -1) {
+		next := []interface{}{}
+		for _, v := range values {
+			arr, ok := v.([]interface{})
+			if !ok {
+				if v != nil || idx[1] != "*" {
+					next = append(next, nil)
+				}
+				continue
+			}
+			if idx[1] == "*" {
+				next = append(next, arr...)
+			} else if i, _ := strconv.Atoi(idx[1]); i < len(arr) {
+				next = append(next, arr[i])
+			} else {
+				next = append(next, nil)
+			}
+		}
+		values = next
+	}
+
+	result := []interface{}{}
+	for _, v := range values {
+		result = append(result, resolve(v, path[1:])...)
+	}
+	return result
+}
+
+func equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(a, b) || fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ContainX/depcon/marathon"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPolicies = `
+policies:
+  - name: pinned-images
+    assert:
+      - { path: container.docker.image, op: matches, value: ":[0-9]" }
+  - name: named-ports
+    action: warn
+    assert:
+      - { path: "container.docker.portMappings[*].name", op: exists }
+  - name: critical-instances
+    when:
+      - { path: labels.tier, op: eq, value: critical }
+    assert:
+      - { path: instances, op: gte, value: 3 }
+  - name: group-owner
+    kind: group
+    assert:
+      - { path: labels, op: absent }
+`
+
+func loadTestPolicies(t *testing.T) *PolicySet {
+	dir, _ := ioutil.TempDir("", "policy")
+	defer os.RemoveAll(dir)
+	f := filepath.Join(dir, "policies.yaml")
+	ioutil.WriteFile(f, []byte(testPolicies), 0644)
+
+	ps, err := Load(f)
+	assert.NoError(t, err)
+	return ps
+}
+
+func TestEvaluateApplication(t *testing.T) {
+	ps := loadTestPolicies(t)
+	app := &marathon.Application{
+		ID:        "/product/api",
+		Instances: 2,
+		Labels:    map[string]string{"tier": "critical"},
+		Container: &marathon.Container{Docker: &marathon.Docker{
+			Image:        "product/api:latest",
+			PortMappings: []*marathon.PortMapping{{Name: "http", ContainerPort: 8080}, {ContainerPort: 8081}},
+		}},
+	}
+
+	violations, err := ps.EvaluateApplication(app)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 3)
+	assert.Equal(t, "pinned-images", violations[0].Policy)
+	assert.Equal(t, ActionDeny, violations[0].Action)
+	assert.Equal(t, "named-ports", violations[1].Policy)
+	assert.Equal(t, ActionWarn, violations[1].Action)
+	assert.Equal(t, "critical-instances", violations[2].Policy)
+
+	app.Instances = 3
+	app.Container.Docker.Image = "product/api:1.2.0"
+	app.Container.Docker.PortMappings[1].Name = "admin"
+	violations, _ = ps.EvaluateApplication(app)
+	assert.Empty(t, violations)
+	assert.NoError(t, ps.VerifyApplication(app))
+}
+
+func TestEvaluateMissingFields(t *testing.T) {
+	ps := loadTestPolicies(t)
+	// instances is omitted from the descriptor when 0 or left to the Marathon default
+	app := &marathon.Application{ID: "/product/api", Labels: map[string]string{"tier": "critical"},
+		Container: &marathon.Container{Docker: &marathon.Docker{Image: "product/api:1.2.0"}}}
+
+	violations, err := ps.EvaluateApplication(app)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 2)
+	assert.Equal(t, "named-ports", violations[0].Policy)
+	assert.Equal(t, "critical-instances", violations[1].Policy)
+
+	ne := &Assertion{Path: "instances", Op: OpNe, Value: 0}
+	assert.True(t, ne.holds(map[string]interface{}{}))
+}
+
+func TestLoadFrom(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "policy")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "policies.yaml"), []byte(testPolicies), 0644)
+
+	ps, err := LoadFrom(dir, "policies.yaml")
+	assert.NoError(t, err)
+	assert.Len(t, ps.Policies, 4)
+
+	_, err = Load("policies.yaml")
+	assert.Error(t, err)
+}
+
+func TestVerifyGroup(t *testing.T) {
+	ps := loadTestPolicies(t)
+	group := &marathon.Group{GroupID: "/product", Apps: []*marathon.Application{
+		{ID: "/product/api", Container: &marathon.Container{Docker: &marathon.Docker{Image: "api"}}},
+	}}
+
+	err := ps.VerifyGroup(group)
+	assert.Error(t, err)
+	perr, ok := err.(*PolicyError)
+	assert.True(t, ok)
+	assert.Len(t, perr.Violations, 1)
+	assert.Equal(t, "/product/api", perr.Violations[0].ID)
+}
+
+func TestValidate(t *testing.T) {
+	ps := &PolicySet{Policies: []*Policy{{Name: "bad", Assert: []*Assertion{{Path: "instances", Op: "approx"}}}}}
+	assert.Error(t, ps.Validate())
+
+	ps = &PolicySet{Policies: []*Policy{{Name: "bad", Assert: []*Assertion{{Path: "ports[x]", Op: OpExists}}}}}
+	assert.Error(t, ps.Validate())
+}
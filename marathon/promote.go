@@ -0,0 +1,79 @@
+package marathon
+
+import (
+	"strings"
+)
+
+// Determines which fields are carried from the source application when promoting it to another environment
+type PromoteOptions struct {
+	// if true the container image is carried over
+	Image bool
+	// label keys to carry over.  If empty, labels whose key contains "version" are carried
+	Labels []string
+	// environment variable keys to carry over (allow-list)
+	EnvKeys []string
+}
+
+// Returns a copy of the {target} definition with the fields selected by {opts} carried over from {source}
+func Promote(source, target *Application, opts *PromoteOptions) *Application {
+	if opts == nil {
+		opts = &PromoteOptions{Image: true}
+	}
+	promoted := target.Descriptor()
+
+	if opts.Image && source.Container != nil && source.Container.Docker != nil {
+		if promoted.Container == nil || promoted.Container.Docker == nil {
+			promoted.Container = source.Container
+		} else {
+			container := *promoted.Container
+			docker := *container.Docker
+			docker.Image = source.Container.Docker.Image
+			container.Docker = &docker
+			promoted.Container = &container
+		}
+	}
+
+	labels := copyLabels(promoted.Labels)
+	for k, v := range source.Labels {
+		if promoteLabel(k, opts.Labels) {
+			labels[k] = v
+		}
+	}
+	if len(labels) > 0 {
+		promoted.Labels = labels
+	}
+
+	if len(opts.EnvKeys) > 0 {
+		env := make(map[string]EnvVar, len(promoted.Env))
+		for k, v := range promoted.Env {
+			env[k] = v
+		}
+		for _, k := range opts.EnvKeys {
+			if v, ok := source.Env[k]; ok {
+				env[k] = v
+			}
+		}
+		promoted.Env = env
+	}
+	return promoted
+}
+
+func promoteLabel(key string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return strings.Contains(strings.ToLower(key), "version")
+	}
+	for _, a := range allowed {
+		if a == key {
+			return true
+		}
+	}
+	return false
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	c := make(map[string]string, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,39 @@
+package marathon
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPromote(t *testing.T) {
+	source := &Application{
+		ID:        "/product/api",
+		Instances: 1,
+		Container: &Container{Docker: &Docker{Image: "api:1.2.0"}},
+		Labels:    map[string]string{"app-version": "1.2.0", "team": "staging-team"},
+		Env:       map[string]EnvVar{"RELEASE": EnvValue("1.2.0"), "DB_HOST": EnvValue("staging-db")},
+	}
+	target := &Application{
+		ID:           "/product/api",
+		Instances:    5,
+		Version:      "2017-06-01T10:00:00.000Z",
+		TasksRunning: 5,
+		Container:    &Container{Docker: &Docker{Image: "api:1.1.0", Network: "BRIDGE"}},
+		Labels:       map[string]string{"app-version": "1.1.0", "team": "prod-team"},
+		Env:          map[string]EnvVar{"RELEASE": EnvValue("1.1.0"), "DB_HOST": EnvValue("prod-db")},
+	}
+
+	promoted := Promote(source, target, &PromoteOptions{Image: true, EnvKeys: []string{"RELEASE"}})
+
+	assert.Equal(t, "api:1.2.0", promoted.Container.Docker.Image)
+	assert.Equal(t, "BRIDGE", promoted.Container.Docker.Network)
+	assert.Equal(t, 5, promoted.Instances)
+	assert.Equal(t, map[string]string{"app-version": "1.2.0", "team": "prod-team"}, promoted.Labels)
+	assert.Equal(t, "1.2.0", promoted.Env["RELEASE"].Value)
+	assert.Equal(t, "prod-db", promoted.Env["DB_HOST"].Value)
+	assert.Equal(t, "", promoted.Version)
+	assert.Equal(t, "api:1.1.0", target.Container.Docker.Image, "target should not be modified")
+
+	changes, _ := Diff(promoted, target)
+	assert.Equal(t, 3, len(changes))
+}
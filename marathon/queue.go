@@ -0,0 +1,94 @@
+package marathon
+
+import (
+	"fmt"
+	"github.com/ContainX/depcon/utils"
+	"sort"
+)
+
+const (
+	PathDelay              = "delay"
+	EmbedLastUnusedOffers  = "lastUnusedOffers"
+	OfferUnfulfilledRole   = "UnfulfilledRole"
+	OfferUnfulfilledConstr = "UnfulfilledConstraint"
+	OfferNoReservation     = "NoCorrespondingReservationFound"
+	OfferAgentMaintenance  = "AgentMaintenance"
+	OfferInsufficientCpus  = "InsufficientCpus"
+	OfferInsufficientMem   = "InsufficientMemory"
+	OfferInsufficientDisk  = "InsufficientDisk"
+	OfferInsufficientGpus  = "InsufficientGpus"
+	OfferInsufficientPorts = "InsufficientPorts"
+	OfferDeclinedScarce    = "DeclinedScarceResources"
+)
+
+func (c *MarathonClient) GetQueuedApplication(id string) (*QueuedTask, error) {
+	log.Debugf("Enter: GetQueuedApplication: %s", id)
+	q := new(Queue)
+	url := fmt.Sprintf("%s?embed=%s", c.marathonUrl(API_QUEUE), EmbedLastUnusedOffers)
+	resp := c.http.HttpGet(url, &q)
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	for idx := range q.Queue {
+		qt := &q.Queue[idx]
+		if qt.App != nil && utils.TrimRootPath(qt.App.ID) == utils.TrimRootPath(id) {
+			return qt, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *MarathonClient) ResetQueueDelay(id string) error {
+	log.Infof("Resetting launch delay for '%s'", id)
+	resp := c.http.HttpDelete(c.marathonUrl(API_QUEUE, utils.TrimRootPath(id), PathDelay), nil, nil)
+	return resp.Error
+}
+
+// Returns the offer reject reasons which declined at least one offer during the last offer
+// cycle, ordered by the most declined first
+func (qt *QueuedTask) DeclineReasons() []*OfferRejectSummary {
+	reasons := []*OfferRejectSummary{}
+	if qt.ProcessedOffersSummary == nil {
+		return reasons
+	}
+	for _, r := range qt.ProcessedOffersSummary.RejectSummaryLastOffers {
+		if r.Declined > 0 {
+			reasons = append(reasons, r)
+		}
+	}
+	sort.SliceStable(reasons, func(i, j int) bool {
+		return reasons[i].Declined > reasons[j].Declined
+	})
+	return reasons
+}
+
+// Returns a human readable explanation of an offer reject reason for the specified application
+func DeclineReasonHint(reason string, app *Application) string {
+	if app == nil {
+		app = &Application{}
+	}
+	switch reason {
+	case OfferUnfulfilledRole:
+		return fmt.Sprintf("offers did not match the accepted resource roles %v", app.AcceptedResourceRoles)
+	case OfferUnfulfilledConstr:
+		return fmt.Sprintf("agents did not satisfy the constraints %v", app.Constraints)
+	case OfferNoReservation:
+		return "no matching resource reservation was found for resident tasks"
+	case OfferAgentMaintenance:
+		return "offering agents are scheduled for maintenance"
+	case OfferInsufficientCpus:
+		return fmt.Sprintf("offers had less than the requested %.2f cpus", app.CPUs)
+	case OfferInsufficientMem:
+		return fmt.Sprintf("offers had less than the requested %.2f MB of memory", app.Mem)
+	case OfferInsufficientDisk:
+		return fmt.Sprintf("offers had less than the requested %.2f MB of disk", app.Disk)
+	case OfferInsufficientGpus:
+		return "offers did not have the requested gpus"
+	case OfferInsufficientPorts:
+		return "offers did not have the requested (host) ports available"
+	case OfferDeclinedScarce:
+		return "offers were declined to avoid consuming scarce resources (eg. gpus)"
+	}
+	return ""
+}
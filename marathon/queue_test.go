@@ -0,0 +1,53 @@
+package marathon
+
+import (
+	"github.com/ContainX/depcon/pkg/mockrest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const (
+	QueueFolder = "testdata/queue/"
+)
+
+func TestGetQueuedApplication(t *testing.T) {
+	s := mockrest.StartNewWithFile(QueueFolder + "queue_offers_response.json")
+	defer s.Stop()
+
+	c := NewMarathonClient(s.URL, "", "", "")
+	qt, err := c.GetQueuedApplication("web/api")
+
+	assert.Nil(t, err, "Error response was not expected")
+	assert.NotNil(t, qt, "Expected queued application")
+	assert.Equal(t, 2, qt.Count)
+	assert.True(t, qt.Delay.Overdue)
+	assert.Equal(t, "10.0.0.10", qt.LastUnusedOffers[0].Offer.Hostname)
+
+	reasons := qt.DeclineReasons()
+	assert.Equal(t, 2, len(reasons), "Expected only reasons which declined offers")
+	assert.Equal(t, OfferInsufficientMem, reasons[0].Reason)
+}
+
+func TestGetQueuedApplicationNotQueued(t *testing.T) {
+	s := mockrest.StartNewWithFile(QueueFolder + "queue_offers_response.json")
+	defer s.Stop()
+
+	c := NewMarathonClient(s.URL, "", "", "")
+	qt, err := c.GetQueuedApplication("/other")
+
+	assert.Nil(t, err, "Error response was not expected")
+	assert.Nil(t, qt)
+}
+
+func TestResetQueueDelay(t *testing.T) {
+	s := mockrest.StartNewWithStatusCode(204)
+	defer s.Stop()
+
+	c := NewMarathonClient(s.URL, "", "", "")
+	err := c.ResetQueueDelay("/web/api")
+	assert.Nil(t, err, "Error response was not expected")
+
+	r := s.TakeRequest()
+	assert.Equal(t, "DELETE", r.Method)
+	assert.Equal(t, "/v2/queue/web/api/delay", r.URL.Path)
+}
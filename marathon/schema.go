@@ -0,0 +1,182 @@
+package marathon
+
+import (
+	"reflect"
+
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/ContainX/depcon/pkg/jsonschema"
+)
+
+var (
+	portProtocols = []interface{}{"tcp", "udp", "tcp,udp", "udp,tcp"}
+
+	schemaReflector = &jsonschema.Reflector{
+		Descriptions: map[string]string{
+			"Application":                            "A Marathon application descriptor",
+			"Application.id":                         "Unique identifier of the application (eg. /product/service/api)",
+			"Application.cmd":                        "The command executed by the shell.  Mutually exclusive with args",
+			"Application.args":                       "The command and arguments executed without a shell.  Mutually exclusive with cmd",
+			"Application.acceptedResourceRoles":      "Mesos resource roles the application accepts offers for (eg. [\"*\"] or [\"slave_public\"])",
+			"Application.constraints":                "Placement constraints of the form [field, operator, value] (eg. [\"hostname\", \"UNIQUE\"])",
+			"Application.container":                  "The container the application runs within",
+			"Application.cpus":                       "CPUs allocated per instance",
+			"Application.disk":                       "Disk space (MB) allocated per instance",
+			"Application.env":                        "Environment variables.  Values are strings or { \"secret\": \"name\" } references",
+			"Application.labels":                     "Labels attached to the application (eg. HAPROXY_GROUP)",
+			"Application.healthChecks":               "Health checks which determine whether tasks are healthy",
+			"Application.readinessChecks":            "Readiness checks which determine whether tasks are ready for traffic during deployments",
+			"Application.instances":                  "Number of instances to run",
+			"Application.mem":                        "Memory (MB) allocated per instance",
+			"Application.ports":                      "Deprecated, use portDefinitions",
+			"Application.servicePorts":               "Service ports used by load balancers",
+			"Application.requirePorts":               "If true the host ports must be exactly the declared ports",
+			"Application.backoffFactor":              "Multiplier applied to backoffSeconds after each failed launch",
+			"Application.backoffSeconds":             "Initial delay before relaunching a failed task",
+			"Application.dependencies":               "Ids of the applications or groups which must be deployed first",
+			"Application.user":                       "The user tasks are run as",
+			"Application.upgradeStrategy":            "Controls how many instances are replaced at a time during deployments",
+			"Application.uris":                       "Deprecated, use fetch",
+			"Application.fetch":                      "Artifacts downloaded into the sandbox before the task starts",
+			"Application.gpus":                       "GPUs allocated per instance",
+			"Application.killSelection":              "Which instances are killed first when scaling down",
+			"Application.maxLaunchDelaySeconds":      "Maximum delay between launch attempts",
+			"Application.networks":                   "Networks the application joins (Marathon 1.5+)",
+			"Application.portDefinitions":            "Ports allocated on the host when not using container port mappings",
+			"Application.role":                       "Mesos role of the application",
+			"Application.secrets":                    "Secrets referenced by env and volumes keyed by name",
+			"Application.taskKillGracePeriodSeconds": "Seconds between SIGTERM and SIGKILL when a task is killed",
+			"Application.unreachableStrategy":        "\"disabled\" or the timeouts after which unreachable instances are replaced and expunged",
+			"Application.residency":                  "Keeps tasks with persistent volumes on the same agent",
+			"Group":                                  "A Marathon group descriptor containing applications and nested groups",
+			"Group.id":                               "Unique identifier of the group (eg. /product)",
+			"Group.apps":                             "Applications within the group",
+			"Group.groups":                           "Nested groups",
+			"Group.dependencies":                     "Ids of the applications or groups which must be deployed first",
+			"Container.type":                         "The containerizer",
+			"Container.portMappings":                 "Port mappings for container networking (Marathon 1.5+)",
+			"Docker.image":                           "The docker image (eg. registry.example.com/team/api:1.0)",
+			"Docker.network":                         "Docker network mode (Marathon < 1.5)",
+			"Docker.forcePullImage":                  "Pull the image every time a task is launched",
+			"Docker.parameters":                      "Arbitrary docker run parameters as key/value pairs",
+			"HealthCheck.protocol":                   "How the health check is performed",
+			"HealthCheck.path":                       "Path requested by HTTP(S) health checks",
+			"HealthCheck.portIndex":                  "Index of the port within the port definitions or mappings to check",
+			"HealthCheck.gracePeriodSeconds":         "Failures are ignored for this long after a task starts",
+			"HealthCheck.maxConsecutiveFailures":     "Consecutive failures after which the task is killed (0 to never kill)",
+			"PortMapping.containerPort":              "Port within the container",
+			"PortMapping.hostPort":                   "Port on the host (0 for a random port)",
+			"PortMapping.servicePort":                "Port used by load balancers (0 for a random port)",
+			"PortMapping.name":                       "Name of the port used by readiness checks and service discovery",
+			"UpgradeStrategy.minimumHealthCapacity":  "Fraction (0.0 - 1.0) of instances which must remain healthy during a deployment",
+			"UpgradeStrategy.maximumOverCapacity":    "Fraction (0.0 - 1.0) of instances which may be started above the target during a deployment",
+			"Pod":                                    "A Marathon pod descriptor (Marathon 1.4+) of containers co-located on the same agent",
+			"Pod.id":                                 "Unique identifier of the pod (eg. /product/pod)",
+			"Pod.containers":                         "Containers launched together within the pod",
+			"Pod.environment":                        "Environment variables of every container.  Values are strings or { \"secret\": \"name\" } references",
+			"Pod.volumes":                            "Volumes which containers mount by name",
+			"Pod.scaling":                            "Number of pod instances to run",
+			"Pod.scheduling":                         "Backoff, upgrade and placement of pod instances",
+			"Pod.executorResources":                  "Resources allocated to the executor running the pod",
+			"PodContainer.name":                      "Name of the container, unique within the pod",
+			"PodContainer.exec":                      "The command executed within the container",
+			"PodContainer.resources":                 "Resources allocated to the container",
+			"PodContainer.endpoints":                 "Ports exposed by the container",
+			"PodContainer.image":                     "The container image",
+			"PodContainer.healthCheck":               "Health check which determines whether the container is healthy",
+			"PodContainer.volumeMounts":              "Pod volumes mounted into the container",
+			"PodContainer.artifacts":                 "Artifacts downloaded into the sandbox before the container starts",
+			"PodImage.id":                            "The image (eg. registry.example.com/team/api:1.0)",
+			"PodEndpoint.hostPort":                   "Port on the host (0 for a random port)",
+			"PodScaling.instances":                   "Number of instances to run",
+			"PodConstraint.operator":                 "Constraint operator (eg. UNIQUE, CLUSTER, GROUP_BY, LIKE, UNLIKE, MAX_PER)",
+		},
+		Enums: map[string][]interface{}{
+			"Application.killSelection": {KillSelectionYoungestFirst, KillSelectionOldestFirst},
+			"Container.type":            {ContainerTypeDocker, ContainerTypeMesos},
+			"Docker.network":            {"BRIDGE", "HOST", "USER", "NONE"},
+			"HealthCheck.protocol": {HealthCheckProtocolHTTP, HealthCheckProtocolHTTPS, HealthCheckProtocolTCP, HealthCheckProtocolCommand,
+				HealthCheckProtocolMesosHTTP, HealthCheckProtocolMesosHTTPS, HealthCheckProtocolMesosTCP},
+			"HealthCheck.ipProtocol":     {"IPv4", "IPv6"},
+			"Network.mode":               {NetworkModeContainer, NetworkModeContainerBridge, NetworkModeHost},
+			"PortDefinition.protocol":    portProtocols,
+			"PortMapping.protocol":       portProtocols,
+			"ReadinessCheck.protocol":    {HealthCheckProtocolHTTP, HealthCheckProtocolHTTPS},
+			"Residency.taskLostBehavior": {"WAIT_FOREVER", "RELAUNCH_AFTER_TIMEOUT"},
+			"Volume.mode":                {"RO", "RW"},
+
+			// pods
+			"PodImage.kind":               {"DOCKER", "APPC"},
+			"PodEndpoint.protocol":        {"tcp", "udp"},
+			"PodHTTPHealthCheck.scheme":   {"HTTP", "HTTPS"},
+			"PodScaling.kind":             {"fixed"},
+			"PodScheduling.killSelection": {KillSelectionYoungestFirst, KillSelectionOldestFirst},
+		},
+		ReadOnly: map[string]bool{
+			"Application.tasks":                 true,
+			"Application.tasksRunning":          true,
+			"Application.tasksStaged":           true,
+			"Application.tasksHealthy":          true,
+			"Application.tasksUnHealthy":        true,
+			"Application.deployments":           true,
+			"Application.version":               true,
+			"Application.versionInfo":           true,
+			"Application.lastTaskFailure":       true,
+			"Application.readinessCheckResults": true,
+			"Group.version":                     true,
+			"Pod.version":                       true,
+		},
+		Types: map[reflect.Type]*jsonschema.Schema{
+			reflect.TypeOf(EnvVar{}): {OneOf: []*jsonschema.Schema{
+				{Type: jsonschema.TypeString},
+				// unquoted YAML values are kept as their literal text
+				{Type: jsonschema.TypeNumber},
+				{Type: jsonschema.TypeBoolean},
+				{Type: jsonschema.TypeObject, Properties: map[string]*jsonschema.Schema{
+					"secret": {Type: jsonschema.TypeString, Description: "Name of the secret within the application's secrets"},
+				}, Required: []string{"secret"}, AdditionalProperties: false},
+			}},
+			reflect.TypeOf(UnreachableStrategy{}): {OneOf: []*jsonschema.Schema{
+				{Type: jsonschema.TypeString, Enum: []interface{}{unreachableDisabled}},
+				{Type: jsonschema.TypeObject, Properties: map[string]*jsonschema.Schema{
+					"inactiveAfterSeconds": {Type: jsonschema.TypeInteger},
+					"expungeAfterSeconds":  {Type: jsonschema.TypeInteger},
+				}, AdditionalProperties: false},
+			}},
+		},
+	}
+)
+
+// Returns the JSON schema of application descriptors
+func ApplicationSchema() *jsonschema.Schema {
+	return schemaReflector.Reflect("Marathon Application", &Application{})
+}
+
+// Returns the JSON schema of group descriptors
+func GroupSchema() *jsonschema.Schema {
+	return schemaReflector.Reflect("Marathon Group", &Group{})
+}
+
+// Returns the JSON schema of pod descriptors
+func PodSchema() *jsonschema.Schema {
+	return schemaReflector.Reflect("Marathon Pod", &Pod{})
+}
+
+// Validates the descriptor {data} against {schema}.  Violations are returned as encoding.DecodeErrors
+// located within {src}
+func validateSchema(schema *jsonschema.Schema, et encoding.EncoderType, data string, src *encoding.Source) error {
+	doc, err := encoding.Decode(et, data)
+	if err != nil {
+		return err
+	}
+	errs := encoding.DecodeErrors{}
+	for _, v := range schema.Validate(doc) {
+		errs = append(errs, &encoding.DecodeError{Path: v.Path(), Message: v.Error(), Keys: v.Keys})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	if src == nil {
+		src = &encoding.Source{}
+	}
+	return src.Locate(et, data, errs)
+}
@@ -0,0 +1,137 @@
+package marathon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	MaskedValue = "********"
+)
+
+// Environment variable names whose literal values are considered sensitive and masked on output
+var sensitiveEnvPattern = regexp.MustCompile(`(?i)(pass(word|wd)?|secret|token|api_?key|private_?key|credential)`)
+
+// An application environment variable which is either a literal value or a reference to
+// a secret declared within the application's secrets.  In JSON the value is represented
+// as a string or as { "secret": "name" }
+type EnvVar struct {
+	Value  string
+	Secret string
+}
+
+type envSecretRef struct {
+	Secret string `json:"secret"`
+}
+
+// Creates a literal environment value
+func EnvValue(value string) EnvVar {
+	return EnvVar{Value: value}
+}
+
+// Creates an environment value which references the secret {name}
+func EnvSecret(name string) EnvVar {
+	return EnvVar{Secret: name}
+}
+
+func (e EnvVar) IsSecret() bool {
+	return e.Secret != ""
+}
+
+func (e EnvVar) String() string {
+	if e.IsSecret() {
+		return fmt.Sprintf("<secret:%s>", e.Secret)
+	}
+	return e.Value
+}
+
+func (e EnvVar) MarshalJSON() ([]byte, error) {
+	if e.IsSecret() {
+		return json.Marshal(envSecretRef{Secret: e.Secret})
+	}
+	return json.Marshal(e.Value)
+}
+
+func (e *EnvVar) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+
+	switch data[0] {
+	case '{':
+		ref := new(envSecretRef)
+		if err := json.Unmarshal(data, ref); err != nil {
+			return err
+		}
+		if ref.Secret == "" {
+			return fmt.Errorf("env value %s must declare a 'secret' name", string(data))
+		}
+		*e = EnvSecret(ref.Secret)
+	case '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*e = EnvValue(s)
+	case 'n':
+		*e = EnvVar{}
+	default:
+		// numbers and booleans (eg. unquoted YAML values) are kept as their literal text
+		*e = EnvValue(string(data))
+	}
+	return nil
+}
+
+// Returns the names of secrets referenced by the environment which are not declared within
+// the application's secrets
+func (app *Application) UndeclaredSecrets() []string {
+	missing := []string{}
+	for _, v := range app.Env {
+		if !v.IsSecret() {
+			continue
+		}
+		if _, ok := app.Secrets[v.Secret]; !ok {
+			missing = append(missing, v.Secret)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Returns true if the environment variable {name} likely holds a sensitive value (eg. DB_PASSWORD)
+func IsSensitiveEnv(name string) bool {
+	return sensitiveEnvPattern.MatchString(name)
+}
+
+// Returns an error naming the secrets referenced by the environment which are not declared.  Marathon
+// would otherwise reject the application with a less helpful validation error
+func (app *Application) checkSecrets() error {
+	if missing := app.UndeclaredSecrets(); len(missing) > 0 {
+		return fmt.Errorf("'%s': env references undeclared secrets: %s (declare them within \"secrets\")", app.ID, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Returns a copy of the application where literal environment values of sensitive
+// variables (eg. *_PASSWORD, *_TOKEN) are masked.  Secret references are left
+// untouched since they never contain the secret value
+func (app *Application) MaskSecrets() *Application {
+	if app == nil || len(app.Env) == 0 {
+		return app
+	}
+
+	masked := *app
+	masked.Env = make(map[string]EnvVar, len(app.Env))
+	for k, v := range app.Env {
+		if !v.IsSecret() && v.Value != "" && IsSensitiveEnv(k) {
+			v = EnvValue(MaskedValue)
+		}
+		masked.Env[k] = v
+	}
+	return &masked
+}
@@ -0,0 +1,65 @@
+package marathon
+
+import (
+	"encoding/json"
+	"github.com/ContainX/depcon/pkg/encoding"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestEnvSecretReferences(t *testing.T) {
+	data := `{
+		"id": "/product/service",
+		"env": { "DB_USER": "app", "DB_PASSWORD": { "secret": "db-password" }, "PORT": "8080" },
+		"secrets": { "db-password": { "source": "/product/db/password" } }
+	}`
+
+	app := new(Application)
+	assert.Nil(t, json.Unmarshal([]byte(data), app))
+	assert.Equal(t, EnvValue("app"), app.Env["DB_USER"])
+	assert.Equal(t, EnvSecret("db-password"), app.Env["DB_PASSWORD"])
+	assert.Empty(t, app.UndeclaredSecrets())
+	assert.Contains(t, app.RequiredCapabilities(), CapabilitySecrets)
+
+	out, err := json.Marshal(app.Env)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"DB_USER": "app", "DB_PASSWORD": {"secret": "db-password"}, "PORT": "8080"}`, string(out))
+}
+
+func TestEnvYamlScalars(t *testing.T) {
+	encoder, _ := encoding.NewEncoder(encoding.YAML)
+	app := new(Application)
+	err := encoder.UnMarshalStr("id: /product/service\nenv:\n  PORT: 8080\n  DEBUG: true\n  API_KEY:\n    secret: api-key\n", app)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "8080", app.Env["PORT"].Value)
+	assert.Equal(t, "true", app.Env["DEBUG"].Value)
+	assert.Equal(t, []string{"api-key"}, app.UndeclaredSecrets())
+}
+
+func TestParseApplicationUndeclaredSecrets(t *testing.T) {
+	c := NewMarathonClient("http://localhost:8080", "", "", "")
+	descriptor := `{"id": "/product/api", "env": {"DB_PASSWORD": {"secret": "db"}, "API_KEY": {"secret": "api"}}, "secrets": {"db": {"source": "/product/db"}}}`
+
+	_, err := c.ParseApplicationFromString(strings.NewReader(descriptor), encoding.JSON, &CreateOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "'/product/api': env references undeclared secrets: api")
+
+	_, err = c.ParseGroupFromString(strings.NewReader(`{"id": "/product", "apps": [`+descriptor+`]}`), encoding.JSON, &CreateOptions{})
+	assert.Error(t, err)
+}
+
+func TestMaskSecrets(t *testing.T) {
+	app := &Application{Env: map[string]EnvVar{
+		"DB_PASSWORD": EnvValue("hunter2"),
+		"AUTH_TOKEN":  EnvSecret("token"),
+		"LOG_LEVEL":   EnvValue("debug"),
+	}}
+
+	masked := app.MaskSecrets()
+	assert.Equal(t, MaskedValue, masked.Env["DB_PASSWORD"].Value)
+	assert.Equal(t, EnvSecret("token"), masked.Env["AUTH_TOKEN"])
+	assert.Equal(t, "debug", masked.Env["LOG_LEVEL"].Value)
+	assert.Equal(t, "hunter2", app.Env["DB_PASSWORD"].Value, "original should not be modified")
+}
@@ -1,6 +1,29 @@
 package marathon
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ContainerTypeDocker = "DOCKER"
+	ContainerTypeMesos  = "MESOS"
+
+	HealthCheckProtocolHTTP       = "HTTP"
+	HealthCheckProtocolHTTPS      = "HTTPS"
+	HealthCheckProtocolTCP        = "TCP"
+	HealthCheckProtocolCommand    = "COMMAND"
+	HealthCheckProtocolMesosHTTP  = "MESOS_HTTP"
+	HealthCheckProtocolMesosHTTPS = "MESOS_HTTPS"
+	HealthCheckProtocolMesosTCP   = "MESOS_TCP"
+
+	NetworkModeContainer       = "container"
+	NetworkModeContainerBridge = "container/bridge"
+	NetworkModeHost            = "host"
+
+	KillSelectionYoungestFirst = "YOUNGEST_FIRST"
+	KillSelectionOldestFirst   = "OLDEST_FIRST"
+)
 
 type AppById struct {
 	App Application `json:"app"`
@@ -15,43 +38,56 @@ type Tasks struct {
 }
 
 type Application struct {
-	ID                    string              `json:"id,omitempty"`
-	Cmd                   string              `json:"cmd,omitempty"`
-	Args                  []string            `json:"args,omitempty"`
-	AcceptedResourceRoles []string            `json:"acceptedResourceRoles,omitempty"`
-	Constraints           [][]string          `json:"constraints,omitempty"`
-	Container             *Container          `json:"container,omitempty"`
-	CPUs                  float64             `json:"cpus,omitempty"`
-	Disk                  float64             `json:"disk,omitempty"`
-	Env                   map[string]string   `json:"env,omitempty"`
-	Labels                map[string]string   `json:"labels,omitempty"`
-	Executor              string              `json:"executor,omitempty"`
-	HealthChecks          []*HealthCheck      `json:"healthChecks,omitempty"`
-	ReadinessChecks       []*ReadinessCheck   `json:"readinessChecks,omitempty"`
-	Instances             int                 `json:"instances,omitempty"`
-	Mem                   float64             `json:"mem,omitempty"`
-	Tasks                 []*Task             `json:"tasks,omitempty"`
-	Ports                 []int               `json:"ports,omitempty"`
-	ServicePorts          []int               `json:"servicePorts,omitempty"`
-	RequirePorts          bool                `json:"requirePorts,omitempty"`
-	BackoffFactor         float64             `json:"backoffFactor,omitempty"`
-	BackoffSeconds        int                 `json:"backoffSeconds,omitempty"`
-	DeploymentID          []map[string]string `json:"deployments,omitempty"`
-	Dependencies          []string            `json:"dependencies,omitempty"`
-	TasksRunning          int                 `json:"tasksRunning,omitempty"`
-	TasksStaged           int                 `json:"tasksStaged,omitempty"`
-	TasksHealthy          int                 `json:"tasksHealthy,omitempty"`
-	TasksUnHealthy        int                 `json:"tasksUnHealthy,omitempty"`
-	TaskIPAddress         *TaskIPAddress      `json:"ipAddress,omitempty"`
-	User                  string              `json:"user,omitempty"`
-	UpgradeStrategy       *UpgradeStrategy    `json:"upgradeStrategy,omitempty"`
-	Uris                  []string            `json:"uris,omitempty"`
-	Version               string              `json:"version,omitempty"`
-	VersionInfo           *VersionInfo        `json:"versionInfo,omitempty"`
-	LastTaskFailure       *LastTaskFailure    `json:"lastTaskFailure,omitempty"`
-	Fetch                 []Fetch             `json:"fetch"`
-	Residency             *Residency          `json:"residency,omitempty"`
-	StoreURLs             []string            `json:"storeUrls,omitempty"`
+	ID                    string                  `json:"id,omitempty"`
+	Cmd                   string                  `json:"cmd,omitempty"`
+	Args                  []string                `json:"args,omitempty"`
+	AcceptedResourceRoles []string                `json:"acceptedResourceRoles,omitempty"`
+	Constraints           [][]string              `json:"constraints,omitempty"`
+	Container             *Container              `json:"container,omitempty"`
+	CPUs                  float64                 `json:"cpus,omitempty"`
+	Disk                  float64                 `json:"disk,omitempty"`
+	Env                   map[string]EnvVar       `json:"env,omitempty"`
+	Labels                map[string]string       `json:"labels,omitempty"`
+	Executor              string                  `json:"executor,omitempty"`
+	HealthChecks          []*HealthCheck          `json:"healthChecks,omitempty"`
+	ReadinessChecks       []*ReadinessCheck       `json:"readinessChecks,omitempty"`
+	ReadinessCheckResults []*ReadinessCheckResult `json:"readinessCheckResults,omitempty"`
+	Instances             int                     `json:"instances,omitempty"`
+	Mem                   float64                 `json:"mem,omitempty"`
+	Tasks                 []*Task                 `json:"tasks,omitempty"`
+	Ports                 []int                   `json:"ports,omitempty"`
+	ServicePorts          []int                   `json:"servicePorts,omitempty"`
+	RequirePorts          bool                    `json:"requirePorts,omitempty"`
+	BackoffFactor         float64                 `json:"backoffFactor,omitempty"`
+	BackoffSeconds        int                     `json:"backoffSeconds,omitempty"`
+	DeploymentID          []map[string]string     `json:"deployments,omitempty"`
+	Dependencies          []string                `json:"dependencies,omitempty"`
+	TasksRunning          int                     `json:"tasksRunning,omitempty"`
+	TasksStaged           int                     `json:"tasksStaged,omitempty"`
+	TasksHealthy          int                     `json:"tasksHealthy,omitempty"`
+	TasksUnHealthy        int                     `json:"tasksUnHealthy,omitempty"`
+	TaskIPAddress         *TaskIPAddress          `json:"ipAddress,omitempty"`
+	User                  string                  `json:"user,omitempty"`
+	UpgradeStrategy       *UpgradeStrategy        `json:"upgradeStrategy,omitempty"`
+	Uris                  []string                `json:"uris,omitempty"`
+	Version               string                  `json:"version,omitempty"`
+	VersionInfo           *VersionInfo            `json:"versionInfo,omitempty"`
+	LastTaskFailure       *LastTaskFailure        `json:"lastTaskFailure,omitempty"`
+	Fetch                 []Fetch                 `json:"fetch"`
+	GPUs                  int                     `json:"gpus,omitempty"`
+	KillSelection         string                  `json:"killSelection,omitempty"`
+	MaxLaunchDelaySeconds int                     `json:"maxLaunchDelaySeconds,omitempty"`
+	Networks              []*Network              `json:"networks,omitempty"`
+	PortDefinitions       []*PortDefinition       `json:"portDefinitions,omitempty"`
+	Role                  string                  `json:"role,omitempty"`
+	Secrets               map[string]*Secret      `json:"secrets,omitempty"`
+	TaskKillGracePeriod   int                     `json:"taskKillGracePeriodSeconds,omitempty"`
+	UnreachableStrategy   *UnreachableStrategy    `json:"unreachableStrategy,omitempty"`
+	Residency             *Residency              `json:"residency,omitempty"`
+	StoreURLs             []string                `json:"storeUrls,omitempty"`
+	// Fields which are not modeled above.  These are preserved when decoding and passed through
+	// to Marathon unchanged when encoding so nothing declared in a descriptor is lost
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type KillTasksScale struct {
@@ -78,16 +114,48 @@ type DeploymentID struct {
 }
 
 type Container struct {
-	Type    string    `json:"type,omitempty"`
-	Docker  *Docker   `json:"docker,omitempty"`
-	Volumes []*Volume `json:"volumes,omitempty"`
+	Type         string                     `json:"type,omitempty"`
+	Docker       *Docker                    `json:"docker,omitempty"`
+	Appc         *Appc                      `json:"appc,omitempty"`
+	PortMappings []*PortMapping             `json:"portMappings,omitempty"`
+	Volumes      []*Volume                  `json:"volumes,omitempty"`
+	Unknown      map[string]json.RawMessage `json:"-"`
+}
+
+type Appc struct {
+	Image     string                     `json:"image,omitempty"`
+	ID        string                     `json:"id,omitempty"`
+	Labels    map[string]string          `json:"labels,omitempty"`
+	ForcePull bool                       `json:"forcePull,omitempty"`
+	Unknown   map[string]json.RawMessage `json:"-"`
+}
+
+type Network struct {
+	Name    string                     `json:"name,omitempty"`
+	Mode    string                     `json:"mode,omitempty"`
+	Labels  map[string]string          `json:"labels,omitempty"`
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+type Secret struct {
+	Source  string                     `json:"source"`
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+type PortDefinition struct {
+	Port     int                        `json:"port"`
+	Protocol string                     `json:"protocol,omitempty"`
+	Name     string                     `json:"name,omitempty"`
+	Labels   map[string]string          `json:"labels,omitempty"`
+	Unknown  map[string]json.RawMessage `json:"-"`
 }
 
 type Fetch struct {
-	URI        string `json:"uri"`
-	Executable bool   `json:"executable"`
-	Extract    bool   `json:"extract"`
-	Cache      bool   `json:"cache"`
+	URI        string                     `json:"uri"`
+	Executable bool                       `json:"executable"`
+	Extract    bool                       `json:"extract"`
+	Cache      bool                       `json:"cache"`
+	Unknown    map[string]json.RawMessage `json:"-"`
 }
 
 type LastTaskFailure struct {
@@ -101,71 +169,129 @@ type LastTaskFailure struct {
 }
 
 type PortMapping struct {
-	Name          string            `json:"name,omitempty"`
-	ContainerPort int               `json:"containerPort,omitempty"`
-	HostPort      int               `json:"hostPort"`
-	ServicePort   int               `json:"servicePort,omitempty"`
-	Protocol      string            `json:"protocol"`
-	Labels        map[string]string `json:"labels,omitempty"`
+	Name          string                     `json:"name,omitempty"`
+	ContainerPort int                        `json:"containerPort,omitempty"`
+	HostPort      int                        `json:"hostPort"`
+	ServicePort   int                        `json:"servicePort,omitempty"`
+	Protocol      string                     `json:"protocol"`
+	Labels        map[string]string          `json:"labels,omitempty"`
+	NetworkNames  []string                   `json:"networkNames,omitempty"`
+	Unknown       map[string]json.RawMessage `json:"-"`
 }
 
 type Parameters struct {
-	Key   string `json:"key,omitempty"`
-	Value string `json:"value,omitempty"`
+	Key     string                     `json:"key,omitempty"`
+	Value   string                     `json:"value,omitempty"`
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type Volume struct {
-	ContainerPath string            `json:"containerPath,omitempty"`
-	HostPath      string            `json:"hostPath,omitempty"`
-	Mode          string            `json:"mode,omitempty"`
-	Persistent    *PersistentVolume `json:"persistent,omitempty"`
-	External      *ExternalVolume   `json:"external,omitempty"`
+	ContainerPath string                     `json:"containerPath,omitempty"`
+	HostPath      string                     `json:"hostPath,omitempty"`
+	Mode          string                     `json:"mode,omitempty"`
+	Secret        string                     `json:"secret,omitempty"`
+	Persistent    *PersistentVolume          `json:"persistent,omitempty"`
+	External      *ExternalVolume            `json:"external,omitempty"`
+	Unknown       map[string]json.RawMessage `json:"-"`
 }
 
 type PersistentVolume struct {
-	Size int `json:"size,omitempty"`
+	Size    int                        `json:"size,omitempty"`
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type ExternalVolume struct {
-	Name     string            `json:"name,omitempty"`
-	Size     int               `json:"size,omitempty"`
-	Provider string            `json:"provider,omitempty"`
-	Options  map[string]string `json:"options,omitempty"`
+	Name     string                     `json:"name,omitempty"`
+	Size     int                        `json:"size,omitempty"`
+	Provider string                     `json:"provider,omitempty"`
+	Options  map[string]string          `json:"options,omitempty"`
+	Unknown  map[string]json.RawMessage `json:"-"`
 }
 
 type Docker struct {
-	ForcePullImage bool           `json:"forcePullImage,omitempty"`
-	Image          string         `json:"image,omitempty"`
-	Network        string         `json:"network,omitempty"`
-	Parameters     []*Parameters  `json:"parameters,omitempty"`
-	PortMappings   []*PortMapping `json:"portMappings,omitempty"`
-	Privileged     bool           `json:"privileged,omitempty"`
+	ForcePullImage bool                       `json:"forcePullImage,omitempty"`
+	Image          string                     `json:"image,omitempty"`
+	Network        string                     `json:"network,omitempty"`
+	Parameters     []*Parameters              `json:"parameters,omitempty"`
+	PortMappings   []*PortMapping             `json:"portMappings,omitempty"`
+	Privileged     bool                       `json:"privileged,omitempty"`
+	PullConfig     *PullConfig                `json:"pullConfig,omitempty"`
+	Unknown        map[string]json.RawMessage `json:"-"`
+}
+
+type PullConfig struct {
+	Secret  string                     `json:"secret"`
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// Defines how Marathon handles unreachable instances.  Marathon accepts either the string "disabled"
+// or an object declaring the timeouts
+type UnreachableStrategy struct {
+	Disabled             bool                       `json:"-"`
+	InactiveAfterSeconds int                        `json:"inactiveAfterSeconds,omitempty"`
+	ExpungeAfterSeconds  int                        `json:"expungeAfterSeconds,omitempty"`
+	Unknown              map[string]json.RawMessage `json:"-"`
+}
+
+const unreachableDisabled = "disabled"
+
+func (u *UnreachableStrategy) UnmarshalJSON(data []byte) error {
+	var mode string
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*u = UnreachableStrategy{Disabled: mode == unreachableDisabled}
+		return nil
+	}
+	type strategy UnreachableStrategy
+	s := new(strategy)
+	unknown, err := decodeWithPassthrough(data, s)
+	if err != nil {
+		return err
+	}
+	*u = UnreachableStrategy(*s)
+	u.Unknown = unknown
+	return nil
+}
+
+func (u UnreachableStrategy) MarshalJSON() ([]byte, error) {
+	if u.Disabled {
+		return json.Marshal(unreachableDisabled)
+	}
+	type strategy UnreachableStrategy
+	return encodeWithPassthrough(strategy(u), u.Unknown)
 }
 
 type UpgradeStrategy struct {
-	MinimumHealthCapacity float64 `json:"minimumHealthCapacity"`
-	MaximumOverCapacity   float64 `json:"maximumOverCapacity"`
+	MinimumHealthCapacity float64                    `json:"minimumHealthCapacity"`
+	MaximumOverCapacity   float64                    `json:"maximumOverCapacity"`
+	Unknown               map[string]json.RawMessage `json:"-"`
 }
 
 type HealthCheck struct {
-	Protocol               string              `json:"protocol,omitempty"`
-	Command                *HealthCheckCommand `json:"command,omitempty"`
-	Path                   string              `json:"path,omitempty"`
-	GracePeriodSeconds     int                 `json:"gracePeriodSeconds,omitempty"`
-	IntervalSeconds        int                 `json:"intervalSeconds,omitempty"`
-	PortIndex              int                 `json:"portIndex,omitempty"`
-	MaxConsecutiveFailures int                 `json:"maxConsecutiveFailures,omitempty"`
-	TimeoutSeconds         int                 `json:"timeoutSeconds,omitempty"`
+	Protocol               string                     `json:"protocol,omitempty"`
+	Command                *HealthCheckCommand        `json:"command,omitempty"`
+	Path                   string                     `json:"path,omitempty"`
+	GracePeriodSeconds     int                        `json:"gracePeriodSeconds,omitempty"`
+	IntervalSeconds        int                        `json:"intervalSeconds,omitempty"`
+	PortIndex              int                        `json:"portIndex,omitempty"`
+	Port                   int                        `json:"port,omitempty"`
+	IPProtocol             string                     `json:"ipProtocol,omitempty"`
+	DelaySeconds           int                        `json:"delaySeconds,omitempty"`
+	IgnoreHttp1xx          bool                       `json:"ignoreHttp1xx,omitempty"`
+	MaxConsecutiveFailures int                        `json:"maxConsecutiveFailures,omitempty"`
+	TimeoutSeconds         int                        `json:"timeoutSeconds,omitempty"`
+	Unknown                map[string]json.RawMessage `json:"-"`
 }
 
 type HealthCheckCommand struct {
-	Value string `json:"value,omitempty"`
+	Value   string                     `json:"value,omitempty"`
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type TaskIPAddress struct {
-	Discovery *Discovery        `json:"discovery,omitempty"`
-	Groups    []string          `json:"groups,omitempty"`
-	Labels    map[string]string `json:"labels,omitempty"`
+	Discovery *Discovery                 `json:"discovery,omitempty"`
+	Groups    []string                   `json:"groups,omitempty"`
+	Labels    map[string]string          `json:"labels,omitempty"`
+	Unknown   map[string]json.RawMessage `json:"-"`
 }
 
 type IPAddress struct {
@@ -174,29 +300,46 @@ type IPAddress struct {
 }
 
 type Discovery struct {
-	Ports []*DiscoveryPorts `json:"ports,omitempty"`
+	Ports   []*DiscoveryPorts          `json:"ports,omitempty"`
+	Unknown map[string]json.RawMessage `json:"-"`
 }
 
 type DiscoveryPorts struct {
-	Name       string `json:"name,omitempty"`
-	Protocol   string `json:"protocol,omitempty"`
-	PortNumber int    `json:"number,omitempty"`
+	Name       string                     `json:"name,omitempty"`
+	Protocol   string                     `json:"protocol,omitempty"`
+	PortNumber int                        `json:"number,omitempty"`
+	Unknown    map[string]json.RawMessage `json:"-"`
 }
 
 type ReadinessCheck struct {
-	Name                 string `json:"name,omitempty"`
-	Protocol             string `json:"protocol,omitempty"`
-	Path                 string `json:"path,omitempty"`
-	PortName             string `json:"portName,omitempty"`
-	IntervalSeconds      int    `json:"intervalSeconds,omitempty"`
-	TimeoutSeconds       int    `json:"timeoutSeconds,omitempty"`
-	HttpStatusCodesReady int    `json:"httpStatusCodesForReady,omitempty"`
-	PreserveLastResponse bool   `json:"preserveLastResponse,omitempty"`
+	Name                 string                     `json:"name,omitempty"`
+	Protocol             string                     `json:"protocol,omitempty"`
+	Path                 string                     `json:"path,omitempty"`
+	PortName             string                     `json:"portName,omitempty"`
+	IntervalSeconds      int                        `json:"intervalSeconds,omitempty"`
+	TimeoutSeconds       int                        `json:"timeoutSeconds,omitempty"`
+	HttpStatusCodesReady []int                      `json:"httpStatusCodesForReady,omitempty"`
+	PreserveLastResponse bool                       `json:"preserveLastResponse,omitempty"`
+	Unknown              map[string]json.RawMessage `json:"-"`
+}
+
+type ReadinessCheckResult struct {
+	Name         string                  `json:"name"`
+	TaskID       string                  `json:"taskId"`
+	Ready        bool                    `json:"ready"`
+	LastResponse *ReadinessCheckResponse `json:"lastResponse,omitempty"`
+}
+
+type ReadinessCheckResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	Body        string `json:"body"`
 }
 
 type Residency struct {
-	RelaunchEscalationTimeoutSeconds int    `json:"relaunchEscalationTimeoutSeconds,omitempty"`
-	TaskLostBehaviour                string `json:"taskLostBehavior,omitempty"`
+	RelaunchEscalationTimeoutSeconds int                        `json:"relaunchEscalationTimeoutSeconds,omitempty"`
+	TaskLostBehaviour                string                     `json:"taskLostBehavior,omitempty"`
+	Unknown                          map[string]json.RawMessage `json:"-"`
 }
 
 type HealthCheckResult struct {
@@ -221,8 +364,58 @@ type Task struct {
 }
 
 type QueuedTask struct {
-	App   *Application    `json:"app"`
-	Delay map[string]bool `json:"delay"`
+	App                    *Application            `json:"app"`
+	Count                  int                     `json:"count"`
+	Delay                  QueueDelay              `json:"delay"`
+	Since                  string                  `json:"since,omitempty"`
+	ProcessedOffersSummary *ProcessedOffersSummary `json:"processedOffersSummary,omitempty"`
+	LastUnusedOffers       []*UnusedOffer          `json:"lastUnusedOffers,omitempty"`
+}
+
+type QueueDelay struct {
+	TimeLeftSeconds int  `json:"timeLeftSeconds"`
+	Overdue         bool `json:"overdue"`
+}
+
+type ProcessedOffersSummary struct {
+	ProcessedOffersCount       int                   `json:"processedOffersCount"`
+	UnusedOffersCount          int                   `json:"unusedOffersCount"`
+	LastUnusedOfferAt          string                `json:"lastUnusedOfferAt,omitempty"`
+	LastUsedOfferAt            string                `json:"lastUsedOfferAt,omitempty"`
+	RejectSummaryLastOffers    []*OfferRejectSummary `json:"rejectSummaryLastOffers,omitempty"`
+	RejectSummaryLaunchAttempt []*OfferRejectSummary `json:"rejectSummaryLaunchAttempt,omitempty"`
+}
+
+type OfferRejectSummary struct {
+	Reason    string `json:"reason"`
+	Declined  int    `json:"declined"`
+	Processed int    `json:"processed"`
+}
+
+type UnusedOffer struct {
+	Offer     *Offer   `json:"offer"`
+	Timestamp string   `json:"timestamp"`
+	Reason    []string `json:"reason"`
+}
+
+type Offer struct {
+	ID        string           `json:"id"`
+	AgentID   string           `json:"agentId"`
+	Hostname  string           `json:"hostname"`
+	Resources []*OfferResource `json:"resources,omitempty"`
+}
+
+type OfferResource struct {
+	Name   string        `json:"name"`
+	Role   string        `json:"role"`
+	Scalar float64       `json:"scalar,omitempty"`
+	Ranges []*OfferRange `json:"ranges,omitempty"`
+	Set    []string      `json:"set,omitempty"`
+}
+
+type OfferRange struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
 }
 
 type Queue struct {
@@ -280,11 +473,12 @@ func (ag *AppOrGroup) IsApplication() bool {
 }
 
 type Group struct {
-	GroupID      string         `json:"id"`
-	Version      string         `json:"version,omitempty"`
-	Apps         []*Application `json:"apps,omitempty"`
-	Dependencies []string       `json:"dependencies,omitempty"`
-	Groups       []*Group       `json:"groups,omitempty"`
+	GroupID      string                     `json:"id"`
+	Version      string                     `json:"version,omitempty"`
+	Apps         []*Application             `json:"apps,omitempty"`
+	Dependencies []string                   `json:"dependencies,omitempty"`
+	Groups       []*Group                   `json:"groups,omitempty"`
+	Unknown      map[string]json.RawMessage `json:"-"`
 }
 
 type Groups struct {
@@ -345,6 +539,17 @@ type MarathonInfo struct {
 	} `json:"zookeeper_config"`
 }
 
+type EventSubscriptions struct {
+	CallbackURLs []string `json:"callbackUrls"`
+}
+
+type EventSubscriptionChange struct {
+	CallbackURL string `json:"callbackUrl"`
+	ClientIP    string `json:"clientIp"`
+	EventType   string `json:"eventType"`
+	Timestamp   string `json:"timestamp"`
+}
+
 type LeaderInfo struct {
 	Leader string `json:"leader"`
 }
@@ -0,0 +1,39 @@
+package marathon
+
+import (
+	"fmt"
+	"net/url"
+)
+
+func (c *MarathonClient) ListEventSubscriptions() (*EventSubscriptions, error) {
+	subs := new(EventSubscriptions)
+	resp := c.http.HttpGet(c.marathonUrl(API_SUBSCRIPTION), subs)
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return subs, nil
+}
+
+func (c *MarathonClient) AddEventSubscription(callbackUrl string) (*EventSubscriptionChange, error) {
+	log.Infof("Adding event subscription '%s'", callbackUrl)
+	change := new(EventSubscriptionChange)
+	resp := c.http.HttpPost(c.subscriptionUrl(callbackUrl), nil, change)
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return change, nil
+}
+
+func (c *MarathonClient) RemoveEventSubscription(callbackUrl string) (*EventSubscriptionChange, error) {
+	log.Infof("Removing event subscription '%s'", callbackUrl)
+	change := new(EventSubscriptionChange)
+	resp := c.http.HttpDelete(c.subscriptionUrl(callbackUrl), nil, change)
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return change, nil
+}
+
+func (c *MarathonClient) subscriptionUrl(callbackUrl string) string {
+	return fmt.Sprintf("%s?callbackUrl=%s", c.marathonUrl(API_SUBSCRIPTION), url.QueryEscape(callbackUrl))
+}
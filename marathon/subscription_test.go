@@ -0,0 +1,34 @@
+package marathon
+
+import (
+	"github.com/ContainX/depcon/pkg/mockrest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestListEventSubscriptions(t *testing.T) {
+	s := mockrest.StartNewWithFile(CommonFolder + "subscriptions_response.json")
+	defer s.Stop()
+
+	c := NewMarathonClient(s.URL, "", "", "")
+	subs, err := c.ListEventSubscriptions()
+
+	assert.Nil(t, err, "Error response was not expected")
+	assert.Equal(t, 2, len(subs.CallbackURLs))
+	assert.Equal(t, "http://hooks.example.com/marathon", subs.CallbackURLs[0])
+}
+
+func TestAddEventSubscription(t *testing.T) {
+	s := mockrest.StartNewWithBody(`{"callbackUrl": "http://hooks.example.com/x?a=1", "clientIp": "10.0.0.1", "eventType": "subscribe_event"}`)
+	defer s.Stop()
+
+	c := NewMarathonClient(s.URL, "", "", "")
+	change, err := c.AddEventSubscription("http://hooks.example.com/x?a=1")
+
+	assert.Nil(t, err, "Error response was not expected")
+	assert.Equal(t, "subscribe_event", change.EventType)
+
+	r := s.TakeRequest()
+	assert.Equal(t, "POST", r.Method)
+	assert.Equal(t, "http://hooks.example.com/x?a=1", r.URL.Query().Get("callbackUrl"))
+}
@@ -8,7 +8,28 @@ import (
 
 var logWait = logger.GetLogger("depcon.deploy.wait")
 
+// Options which determine what state an application must reach before a wait is considered complete
+type WaitOptions struct {
+	// if true will wait until all tasks are passing their health checks
+	Healthy bool
+	// if true will wait until all tasks are passing their readiness checks
+	Ready bool
+}
+
 func (c *MarathonClient) WaitForApplication(id string, timeout time.Duration) error {
+	return c.waitForApplication(id, timeout, nil)
+}
+
+func (c *MarathonClient) WaitForApplicationWithOptions(id string, timeout time.Duration, opts *WaitOptions) error {
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	return c.waitForApplication(id, timeout, opts)
+}
+
+// Waits for all deployments of an application to complete.  If {opts} is nil then health and readiness
+// are waited on only when the application declares the checks and any failure is logged instead of returned
+func (c *MarathonClient) waitForApplication(id string, timeout time.Duration, opts *WaitOptions) error {
 	t_now := time.Now()
 	t_stop := t_now.Add(timeout)
 
@@ -18,26 +39,49 @@ func (c *MarathonClient) WaitForApplication(id string, timeout time.Duration) er
 			return ErrorTimeout
 		}
 
-		app, err := c.GetApplication(id)
+		app, err := c.GetApplicationWithEmbed(id, EmbedAppReadiness)
 		if err == nil {
 			if app.DeploymentID == nil || len(app.DeploymentID) <= 0 {
 				logWait.Infof("Application deployment has completed for %s, elapsed time %s", id, utils.ElapsedStr(time.Since(t_now)))
-				if app.HealthChecks != nil && len(app.HealthChecks) > 0 {
-					err := c.WaitForApplicationHealthy(id, timeout)
-					if err != nil {
-						logWait.Errorf("Error waiting for application '%s' to become healthy: %s", id, err.Error())
-					}
-				} else {
-					logWait.Warningf("No health checks defined for '%s', skipping waiting for healthy state", id)
-				}
-				return nil
+				return c.waitForApplicationChecks(app, t_stop.Sub(time.Now()), opts)
 			}
+			c.logReadinessProgress(app)
 		}
 		c.logWaitApplication(id)
 		time.Sleep(time.Duration(2) * time.Second)
 	}
 }
 
+func (c *MarathonClient) waitForApplicationChecks(app *Application, timeout time.Duration, opts *WaitOptions) error {
+	strict := opts != nil
+	if opts == nil {
+		opts = &WaitOptions{Healthy: true, Ready: len(app.ReadinessChecks) > 0}
+	}
+
+	if opts.Ready {
+		if err := c.WaitForApplicationReady(app.ID, timeout); err != nil {
+			if strict {
+				return err
+			}
+			logWait.Errorf("Error waiting for application '%s' to become ready: %s", app.ID, err.Error())
+		}
+	}
+
+	if opts.Healthy {
+		if app.HealthChecks != nil && len(app.HealthChecks) > 0 {
+			if err := c.WaitForApplicationHealthy(app.ID, timeout); err != nil {
+				if strict {
+					return err
+				}
+				logWait.Errorf("Error waiting for application '%s' to become healthy: %s", app.ID, err.Error())
+			}
+		} else {
+			logWait.Warningf("No health checks defined for '%s', skipping waiting for healthy state", app.ID)
+		}
+	}
+	return nil
+}
+
 func (c *MarathonClient) WaitForApplicationHealthy(id string, timeout time.Duration) error {
 	t_now := time.Now()
 	t_stop := t_now.Add(timeout)
@@ -61,6 +105,70 @@ func (c *MarathonClient) WaitForApplicationHealthy(id string, timeout time.Durat
 	}
 }
 
+func (c *MarathonClient) WaitForApplicationReady(id string, timeout time.Duration) error {
+	t_now := time.Now()
+	t_stop := t_now.Add(timeout)
+	duration := time.Duration(2) * time.Second
+	for {
+		if time.Now().After(t_stop) {
+			return ErrorTimeout
+		}
+		app, err := c.GetApplicationWithEmbed(id, EmbedAppReadiness)
+		if err != nil {
+			return err
+		}
+		if len(app.ReadinessChecks) == 0 {
+			logWait.Warningf("No readiness checks defined for '%s', skipping waiting for ready state", id)
+			return nil
+		}
+		ready, total := readinessProgress(app)
+		if ready == total && len(app.DeploymentID) == 0 {
+			logWait.Infof("All tasks for '%s' have passed readiness checks.  Elapsed readiness check time of %s", id, utils.ElapsedStr(time.Since(t_now)))
+			return nil
+		}
+		c.logReadinessProgress(app)
+		time.Sleep(duration)
+	}
+}
+
+// Returns the number of tasks which are ready vs the total tasks reporting readiness results.  Marathon
+// only reports results for tasks which are part of an active deployment
+func readinessProgress(app *Application) (ready, total int) {
+	tasks := map[string]bool{}
+	for _, r := range app.ReadinessCheckResults {
+		if isReady, found := tasks[r.TaskID]; found {
+			tasks[r.TaskID] = isReady && r.Ready
+		} else {
+			tasks[r.TaskID] = r.Ready
+		}
+	}
+	for _, isReady := range tasks {
+		if isReady {
+			ready++
+		}
+	}
+	return ready, len(tasks)
+}
+
+func (c *MarathonClient) logReadinessProgress(app *Application) {
+	if len(app.ReadinessCheckResults) == 0 {
+		return
+	}
+	ready, total := readinessProgress(app)
+	c.logOutput(logWait.Infof, "%v of %v tasks are ready for %s", ready, total, app.ID)
+
+	for _, r := range app.ReadinessCheckResults {
+		if r.Ready {
+			continue
+		}
+		if r.LastResponse != nil {
+			logWait.Infof("  task %s is not ready (check: %s, last status: %d)", r.TaskID, r.Name, r.LastResponse.Status)
+		} else {
+			logWait.Infof("  task %s is not ready (check: %s, no response yet)", r.TaskID, r.Name)
+		}
+	}
+}
+
 func (c *MarathonClient) WaitForDeployment(id string, timeout time.Duration) error {
 
 	t_now := time.Now()
@@ -0,0 +1,34 @@
+package marathon
+
+import (
+	"github.com/ContainX/depcon/pkg/mockrest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReadinessProgress(t *testing.T) {
+	app := &Application{
+		ReadinessCheckResults: []*ReadinessCheckResult{
+			{Name: "ready", TaskID: "a", Ready: true},
+			{Name: "ready", TaskID: "b", Ready: false},
+			{Name: "other", TaskID: "a", Ready: false},
+			{Name: "ready", TaskID: "c", Ready: true},
+		},
+	}
+	ready, total := readinessProgress(app)
+	assert.Equal(t, 1, ready)
+	assert.Equal(t, 3, total)
+}
+
+func TestWaitForApplicationReady(t *testing.T) {
+	s := mockrest.StartNewWithFile(AppsFolder + "get_app_readiness_response.json")
+	defer s.Stop()
+
+	c := NewMarathonClient(s.URL, "", "", "")
+	err := c.WaitForApplicationReady("/web/frontend", time.Duration(5)*time.Second)
+	assert.Nil(t, err, "Error response was not expected")
+
+	r := s.TakeRequest()
+	assert.Equal(t, EmbedAppReadiness, r.URL.Query().Get("embed"))
+}
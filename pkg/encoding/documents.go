@@ -0,0 +1,116 @@
+package encoding
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var documentSeparator = regexp.MustCompile(`^---([ \t].*)?$`)
+
+// A single document within a (possibly multi-document) file
+type Document struct {
+	Content string
+	// Lines of the file preceding the document
+	LineOffset int
+}
+
+// Splits {data} into its documents.  YAML documents are separated by '---' lines and documents
+// without any content (eg. only comments) are skipped.  JSON data is always a single document
+func SplitDocuments(et EncoderType, data string) []*Document {
+	if et != YAML {
+		return []*Document{{Content: data}}
+	}
+
+	docs := []*Document{}
+	lines := strings.Split(data, "\n")
+	start := 0
+	add := func(end int) {
+		content := strings.Join(lines[start:end], "\n")
+		if hasContent(content) {
+			docs = append(docs, &Document{Content: content, LineOffset: start})
+		}
+	}
+	for i, line := range lines {
+		if documentSeparator.MatchString(strings.TrimRight(line, "\r")) {
+			add(i)
+			start = i + 1
+		}
+	}
+	add(len(lines))
+	return docs
+}
+
+// Returns true if {data} contains more than one document
+func IsMultiDocument(et EncoderType, data string) bool {
+	return len(SplitDocuments(et, data)) > 1
+}
+
+func hasContent(doc string) bool {
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && line != "..." {
+			return true
+		}
+	}
+	return false
+}
+
+// Converts every document within {infile} to the format of {outfile}.  {valueFor} returns the value
+// a document is decoded into.  Multiple documents are written to a multi-document YAML file or, since
+// JSON cannot hold more than one document, to a JSON file per document suffixed with its number
+// (eg. apps-2.json).  Returns the files written
+func ConvertDocuments(infile, outfile string, valueFor func(doc string, et EncoderType) (interface{}, error)) ([]string, error) {
+	fromType, err := EncoderTypeFromExt(infile)
+	if err != nil {
+		return nil, err
+	}
+	toType, err := EncoderTypeFromExt(outfile)
+	if err != nil {
+		return nil, err
+	}
+	fromEnc, _ := NewEncoder(fromType)
+	toEnc, _ := NewEncoder(toType)
+
+	data, err := ioutil.ReadFile(infile)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := []string{}
+	for _, doc := range SplitDocuments(fromType, string(data)) {
+		v, err := valueFor(doc.Content, fromType)
+		if err != nil {
+			return nil, err
+		}
+		if err := fromEnc.UnMarshalStr(doc.Content, v); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", infile, doc.LineOffset+1, err.Error())
+		}
+		out, err := toEnc.MarshalIndent(v)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, out)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outfile), 0700); err != nil {
+		return nil, err
+	}
+	if toType == YAML || len(converted) == 1 {
+		return []string{outfile}, ioutil.WriteFile(outfile, []byte(strings.Join(converted, "---\n")), 0600)
+	}
+
+	ext := filepath.Ext(outfile)
+	written := []string{}
+	for i, out := range converted {
+		f := fmt.Sprintf("%s-%d%s", strings.TrimSuffix(outfile, ext), i+1, ext)
+		if err := ioutil.WriteFile(f, []byte(out), 0600); err != nil {
+			return written, err
+		}
+		written = append(written, f)
+	}
+	return written, nil
+}
@@ -0,0 +1,69 @@
+package encoding
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const multiYAML = `# apps of the product
+---
+id: /product/api
+---
+# nothing here
+---
+id: /product/web
+ports:
+  - port: 80
+`
+
+func TestSplitDocuments(t *testing.T) {
+	docs := SplitDocuments(YAML, multiYAML)
+	assert.Len(t, docs, 2)
+	assert.Equal(t, "id: /product/api", docs[0].Content)
+	assert.Equal(t, 2, docs[0].LineOffset)
+	assert.Equal(t, 6, docs[1].LineOffset)
+
+	assert.True(t, IsMultiDocument(YAML, multiYAML))
+	assert.False(t, IsMultiDocument(YAML, "id: /product/api\n"))
+	assert.Len(t, SplitDocuments(JSON, `{"id": "/product/api"}`), 1)
+}
+
+func TestUnMarshalStrictDocumentOffset(t *testing.T) {
+	doc := SplitDocuments(YAML, multiYAML)[1]
+	err := UnMarshalStrict(YAML, doc.Content+"nmae: web\n", &testApp{}, &Source{Filename: "apps.yaml", LineOffset: doc.LineOffset})
+	errs, ok := err.(DecodeErrors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "apps.yaml:10:1", errs[0].Location())
+}
+
+func TestConvertDocuments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "depcon-convert")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "apps.yaml")
+	assert.NoError(t, ioutil.WriteFile(in, []byte(multiYAML), 0600))
+	valueFor := func(doc string, et EncoderType) (interface{}, error) {
+		return &testApp{}, nil
+	}
+
+	written, err := ConvertDocuments(in, filepath.Join(dir, "apps.json"), valueFor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "apps-1.json"), filepath.Join(dir, "apps-2.json")}, written)
+
+	data, _ := ioutil.ReadFile(written[1])
+	app := &testApp{}
+	assert.NoError(t, UnMarshalStrict(JSON, string(data), app, nil))
+	assert.Equal(t, "/product/web", app.ID)
+
+	written, err = ConvertDocuments(in, filepath.Join(dir, "out.yaml"), valueFor)
+	assert.NoError(t, err)
+	assert.Len(t, written, 1)
+	data, _ = ioutil.ReadFile(written[0])
+	assert.Len(t, SplitDocuments(YAML, string(data)), 2)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type EncoderType int
@@ -18,6 +19,7 @@ const (
 
 var (
 	ErrorInvalidExtension = errors.New("File extension must be [.json | .yml | .yaml]")
+	ErrorInvalidFormat    = errors.New("Format must be [json | yaml]")
 	defaultJSONEncoder    = newJSONEncoder()
 	defaultYAMLEncoder    = newYAMLEncoder()
 )
@@ -71,6 +73,41 @@ func EncoderTypeFromExt(filename string) (EncoderType, error) {
 
 }
 
+// Returns the encoder type of the {format} name (json | yaml | yml)
+func EncoderTypeFromFormat(format string) (EncoderType, error) {
+	switch strings.ToLower(format) {
+	case "yml", "yaml":
+		return YAML, nil
+	case "json":
+		return JSON, nil
+	}
+	return JSON, ErrorInvalidFormat
+}
+
+// Guesses the encoder type of {data} which has no filename.  JSON documents start with an object or
+// array, anything else is treated as YAML
+func SniffEncoderType(data string) EncoderType {
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "{") || strings.HasPrefix(line, "[") {
+			return JSON
+		}
+		return YAML
+	}
+	return YAML
+}
+
+// Returns the file extension (including the dot) used for the encoder type
+func (et EncoderType) Extension() string {
+	if et == YAML {
+		return ".yaml"
+	}
+	return ".json"
+}
+
 func ConvertFile(infile, outfile string, dataType interface{}) error {
 	var fromEnc, toEnc Encoder
 	var encErr error
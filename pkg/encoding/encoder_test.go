@@ -0,0 +1,29 @@
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderTypeFromFormat(t *testing.T) {
+	et, err := EncoderTypeFromFormat("YAML")
+	assert.NoError(t, err)
+	assert.Equal(t, YAML, et)
+
+	et, err = EncoderTypeFromFormat("json")
+	assert.NoError(t, err)
+	assert.Equal(t, JSON, et)
+
+	_, err = EncoderTypeFromFormat("toml")
+	assert.Equal(t, ErrorInvalidFormat, err)
+}
+
+func TestSniffEncoderType(t *testing.T) {
+	assert.Equal(t, JSON, SniffEncoderType("\n  {\"id\": \"/product/api\"}"))
+	assert.Equal(t, JSON, SniffEncoderType("[{\"id\": \"/product/api\"}]"))
+	assert.Equal(t, YAML, SniffEncoderType("# product api\nid: /product/api\n"))
+	assert.Equal(t, YAML, SniffEncoderType("---\nid: /product/api\n"))
+	assert.Equal(t, ".yaml", YAML.Extension())
+	assert.Equal(t, ".json", JSON.Extension())
+}
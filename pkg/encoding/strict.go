@@ -0,0 +1,422 @@
+package encoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+var yamlLineRegex = regexp.MustCompile(`line (\d+): (.*)$`)
+
+// Identifies the descriptor being decoded so strict decoding errors can reference the original file
+type Source struct {
+	// The filename reported within errors
+	Filename string
+	// The original content (eg. before template rendering and ${PARAM} substitution).  Locations are
+	// reported against it when the offending text can be found.  If empty the decoded data is used
+	Content string
+	// Lines of the file preceding the content (eg. earlier documents of a multi-document file)
+	LineOffset int
+}
+
+// A problem found while strictly decoding a descriptor.  Line and Column are 1 based and zero
+// when the location could not be determined
+type DecodeError struct {
+	Filename string
+	Line     int
+	Column   int
+	// Path of the offending field (eg. container.docker.portMappings[0].name)
+	Path    string
+	Message string
+	// The closest valid field name for an unknown field
+	Suggestion string
+	// Path segments used to locate the field.  Array indexes are appended to the preceding segment
+	Keys []string `json:"-"`
+}
+
+// Returns the file:line:column of the error omitting the parts which are unknown
+func (e *DecodeError) Location() string {
+	loc := e.Filename
+	if e.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, e.Line)
+		if e.Column > 0 {
+			loc = fmt.Sprintf("%s:%d", loc, e.Column)
+		}
+	}
+	return strings.TrimPrefix(loc, ":")
+}
+
+// Returns the message along with the suggestion if any
+func (e *DecodeError) Detail() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("%s, did you mean '%s'?", e.Message, e.Suggestion)
+	}
+	return e.Message
+}
+
+func (e *DecodeError) Error() string {
+	if loc := e.Location(); loc != "" {
+		return loc + ": " + e.Detail()
+	}
+	return e.Detail()
+}
+
+// Returned by UnMarshalStrict containing every problem found within the descriptor
+type DecodeErrors []*DecodeError
+
+func (e DecodeErrors) Error() string {
+	msgs := []string{}
+	for _, de := range e {
+		msgs = append(msgs, de.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unmarshals {data} of type {et} into {result} rejecting any fields which {result} does not declare.
+// Field names are matched case insensitively like encoding/json.  Syntax errors, type errors and
+// unknown fields are returned as DecodeErrors located within {src}
+//
+// {et}     - the encoding of {data}
+// {data}   - the descriptor to decode
+// {result} - pointer to the value to decode into
+// {src}    - the file {data} originates from, may be nil
+func UnMarshalStrict(et EncoderType, data string, result interface{}, src *Source) error {
+	if src == nil {
+		src = &Source{}
+	}
+
+	b := []byte(data)
+	if et == YAML {
+		converted, err := yaml.YAMLToJSON(b)
+		if err != nil {
+			return DecodeErrors{src.yamlError(data, err)}
+		}
+		b = converted
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		de := &DecodeError{Filename: src.Filename, Message: err.Error()}
+		if serr, ok := err.(*json.SyntaxError); ok {
+			line, col := position(data, int(serr.Offset)-1)
+			de.Line, de.Column = src.offset(src.mapPosition(data, line, col))
+		}
+		return DecodeErrors{de}
+	}
+
+	errs := unknownFields(doc, reflect.TypeOf(result), []string{})
+	if len(errs) == 0 {
+		if err := json.Unmarshal(b, result); err != nil {
+			errs = append(errs, typeError(err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return src.Locate(et, data, errs)
+}
+
+// Decodes {data} of type {et} into generic maps, slices and values
+func Decode(et EncoderType, data string) (interface{}, error) {
+	b := []byte(data)
+	if et == YAML {
+		converted, err := yaml.YAMLToJSON(b)
+		if err != nil {
+			return nil, err
+		}
+		b = converted
+	}
+	var doc interface{}
+	err := json.Unmarshal(b, &doc)
+	return doc, err
+}
+
+// Sets the filename, line and column of {errs} found within {data} from their Keys and orders them
+// by line
+func (src *Source) Locate(et EncoderType, data string, errs DecodeErrors) DecodeErrors {
+	for _, de := range errs {
+		de.Filename = src.Filename
+		de.Line, de.Column = src.offset(src.locate(et, data, de.Keys))
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+	return errs
+}
+
+func typeError(err error) *DecodeError {
+	terr, ok := err.(*json.UnmarshalTypeError)
+	if !ok || terr.Field == "" {
+		return &DecodeError{Message: err.Error()}
+	}
+	return &DecodeError{
+		Path:    terr.Field,
+		Message: fmt.Sprintf("field '%s' must be of type %s, not %s", terr.Field, terr.Type.String(), terr.Value),
+		Keys:    strings.Split(terr.Field, "."),
+	}
+}
+
+// Returns the fields within {doc} which are not declared by {t}
+func unknownFields(doc interface{}, t reflect.Type, keys []string) DecodeErrors {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	errs := DecodeErrors{}
+
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		names := sortedKeys(v)
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for _, k := range names {
+				path := append(append([]string{}, keys...), k)
+				f, ok := fields[strings.ToLower(k)]
+				if !ok {
+					errs = append(errs, &DecodeError{
+						Path:       formatPath(path),
+						Message:    fmt.Sprintf("unknown field '%s'", formatPath(path)),
+						Suggestion: closestField(k, fields),
+						Keys:       path,
+					})
+					continue
+				}
+				errs = append(errs, unknownFields(v[k], f.Type, path)...)
+			}
+		case reflect.Map:
+			for _, k := range names {
+				errs = append(errs, unknownFields(v[k], t.Elem(), append(append([]string{}, keys...), k))...)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range v {
+				path := append([]string{}, keys...)
+				if len(path) > 0 {
+					path[len(path)-1] = fmt.Sprintf("%s[%d]", path[len(path)-1], i)
+				}
+				errs = append(errs, unknownFields(item, t.Elem(), path)...)
+			}
+		}
+	}
+	return errs
+}
+
+// Returns the exported fields of the struct type {t} keyed by their lower cased JSON name.  Fields of
+// embedded structs without a JSON name are promoted
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			et := f.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				for k, ef := range jsonFields(et) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = ef
+					}
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		f.Name = name
+		fields[strings.ToLower(name)] = f
+	}
+	return fields
+}
+
+// Returns the JSON name of the field closest to {name} or an empty string if none are similar enough
+func closestField(name string, fields map[string]reflect.StructField) string {
+	best, bestDist := "", -1
+	for _, k := range sortedFieldKeys(fields) {
+		d := levenshtein(strings.ToLower(name), k)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = fields[k].Name, d
+		}
+	}
+	max := len(name) / 3
+	if max < 2 {
+		max = 2
+	}
+	if bestDist < 0 || bestDist > max {
+		return ""
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, minInt(cur[j-1]+1, prev[j-1]+cost))
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFieldKeys(m map[string]reflect.StructField) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatPath(keys []string) string {
+	return strings.Join(keys, ".")
+}
+
+// Converts a yaml error into a DecodeError located within the source
+func (src *Source) yamlError(data string, err error) *DecodeError {
+	de := &DecodeError{Filename: src.Filename, Message: err.Error()}
+	if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		de.Message = "yaml: " + m[2]
+		de.Line, _ = src.offset(src.mapPosition(data, line, 0))
+	}
+	return de
+}
+
+// Adds the line offset of the source to a known {line}
+func (src *Source) offset(line, col int) (int, int) {
+	if line > 0 {
+		line += src.LineOffset
+	}
+	return line, col
+}
+
+// Locates the field {keys} by searching for each key in turn within the source (or {data} if the
+// source content is unknown)
+func (src *Source) locate(et EncoderType, data string, keys []string) (int, int) {
+	text := src.Content
+	if text == "" {
+		text = data
+	}
+	if len(keys) == 0 {
+		return 0, 0
+	}
+
+	offset, pos := 0, -1
+	for _, k := range keys {
+		if i := strings.Index(k, "["); i > 0 {
+			k = k[:i]
+		}
+		m := keyPattern(et, k).FindStringSubmatchIndex(text[offset:])
+		if m == nil {
+			return 0, 0
+		}
+		pos = offset + m[2]
+		offset += m[1]
+	}
+	return position(text, pos)
+}
+
+func keyPattern(et EncoderType, key string) *regexp.Regexp {
+	if et == YAML {
+		return regexp.MustCompile(`(?m)^[ \t]*(?:-[ \t]+)*(["']?` + regexp.QuoteMeta(key) + `["']?)[ \t]*:(?:[ \t]|$)`)
+	}
+	return regexp.MustCompile(`("` + regexp.QuoteMeta(key) + `")\s*:`)
+}
+
+// Maps the {line} and {col} within {data} to the source content.  The line is matched by its text
+// (nearest to the original line number) since template rendering may add or remove lines
+func (src *Source) mapPosition(data string, line, col int) (int, int) {
+	if src.Content == "" || line <= 0 {
+		return line, col
+	}
+	dataLines := strings.Split(data, "\n")
+	if line > len(dataLines) {
+		return line, col
+	}
+	target := dataLines[line-1]
+	trimmed := strings.TrimSpace(target)
+
+	srcLines := strings.Split(src.Content, "\n")
+	best := -1
+	for i, l := range srcLines {
+		if trimmed != "" && strings.TrimSpace(l) == trimmed && (best < 0 || absInt(i+1-line) < absInt(best+1-line)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		if len(srcLines) == len(dataLines) {
+			return line, col
+		}
+		return 0, 0
+	}
+	if col > 0 {
+		col += indent(srcLines[best]) - indent(target)
+	}
+	return best + 1, col
+}
+
+func indent(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " \t"))
+}
+
+func absInt(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// Returns the 1 based line and column of the byte {offset} within {text}
+func position(text string, offset int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(text) {
+		offset = len(text)
+	}
+	line := strings.Count(text[:offset], "\n") + 1
+	col := offset - strings.LastIndex(text[:offset], "\n")
+	return line, col
+}
@@ -0,0 +1,87 @@
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPort struct {
+	Name string `json:"name,omitempty"`
+	Port int    `json:"port"`
+}
+
+type testApp struct {
+	ID           string            `json:"id"`
+	HealthChecks []string          `json:"healthChecks,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Ports        []*testPort       `json:"ports,omitempty"`
+}
+
+const strictYAML = `id: /product/api
+healthcheck:
+  - http
+labels:
+  tier: web
+ports:
+  - name: http
+    port: 8080
+  - nmae: admin
+    port: 8081
+`
+
+func TestUnMarshalStrictYAML(t *testing.T) {
+	app := &testApp{}
+	err := UnMarshalStrict(YAML, strictYAML, app, &Source{Filename: "app.yaml"})
+	assert.Error(t, err)
+
+	errs, ok := err.(DecodeErrors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 2)
+
+	assert.Equal(t, "healthcheck", errs[0].Path)
+	assert.Equal(t, "healthChecks", errs[0].Suggestion)
+	assert.Equal(t, "app.yaml:2:1", errs[0].Location())
+
+	assert.Equal(t, "ports[1].nmae", errs[1].Path)
+	assert.Equal(t, "name", errs[1].Suggestion)
+	assert.Equal(t, "app.yaml:9:5", errs[1].Location())
+	assert.Equal(t, "app.yaml:9:5: unknown field 'ports[1].nmae', did you mean 'name'?", errs[1].Error())
+}
+
+func TestUnMarshalStrictLocatesAgainstSource(t *testing.T) {
+	source := `{
+  {{ if .prod }}"id": "/product/api",{{ end }}
+  "id": "${APP_ID}",
+  "portz": []
+}`
+	rendered := `{
+  "id": "/product/api",
+  "portz": []
+}`
+	err := UnMarshalStrict(JSON, rendered, &testApp{}, &Source{Filename: "app.json", Content: source})
+	errs := err.(DecodeErrors)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "app.json:4:3", errs[0].Location())
+	assert.Equal(t, "ports", errs[0].Suggestion)
+}
+
+func TestUnMarshalStrictSyntaxError(t *testing.T) {
+	data := "{\n  \"id\": \"/api\",\n  \"labels\": { \"tier\" \"web\" }\n}"
+	err := UnMarshalStrict(JSON, data, &testApp{}, &Source{Filename: "app.json"})
+	errs := err.(DecodeErrors)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Equal(t, 22, errs[0].Column)
+
+	err = UnMarshalStrict(YAML, "id: /api\nlabels:\n  tier: web\n bad: [", &testApp{}, &Source{Filename: "app.yaml"})
+	errs = err.(DecodeErrors)
+	assert.Equal(t, "app.yaml", errs[0].Filename)
+	assert.True(t, errs[0].Line > 0)
+}
+
+func TestUnMarshalStrictValid(t *testing.T) {
+	app := &testApp{}
+	assert.NoError(t, UnMarshalStrict(YAML, "id: /api\nHealthChecks: [http]\nlabels:\n  anything: goes\n", app, nil))
+	assert.Equal(t, "/api", app.ID)
+	assert.Equal(t, []string{"http"}, app.HealthChecks)
+}
@@ -0,0 +1,157 @@
+// Local append-only journal of the changes made by depcon
+package journal
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The journal filename within the configuration directory
+	FileName = "journal.log"
+
+	OutcomeSuccess = "success"
+	OutcomeFailed  = "failed"
+)
+
+// A single recorded change
+type Entry struct {
+	Timestamp      time.Time `json:"timestamp"`
+	User           string    `json:"user"`
+	Env            string    `json:"env"`
+	ID             string    `json:"id"`
+	Action         string    `json:"action"`
+	DeploymentID   string    `json:"deploymentId,omitempty"`
+	DescriptorHash string    `json:"descriptorHash,omitempty"`
+	Outcome        string    `json:"outcome"`
+	Error          string    `json:"error,omitempty"`
+	Elapsed        string    `json:"elapsed"`
+	// The reason given when the change was made during a change freeze
+	FreezeOverride string `json:"freezeOverride,omitempty"`
+}
+
+// Restricts the entries returned by a query.  Empty fields match everything
+type Filter struct {
+	// App or group id.  Matches the id itself and anything beneath it
+	ID string
+	// Environment name
+	Env string
+	// Max number of entries to return (most recent), 0 for all
+	Limit int
+}
+
+// A journal of entries stored as JSON lines
+type Journal struct {
+	filename string
+	mu       sync.Mutex
+}
+
+// Creates a journal stored within {dir}
+func New(dir string) *Journal {
+	return &Journal{filename: filepath.Join(dir, FileName)}
+}
+
+func (j *Journal) Filename() string {
+	return j.filename
+}
+
+// Appends the entry {e} to the journal.  Timestamp and User are filled in if not specified
+func (j *Journal) Append(e *Entry) error {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	if e.User == "" {
+		e.User = CurrentUser()
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.filename), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Returns the entries matching the {filter} in the order they were recorded.  A missing journal
+// returns no entries.  Lines which cannot be parsed are skipped
+func (j *Journal) Query(filter *Filter) ([]*Entry, error) {
+	entries := []*Entry{}
+
+	f, err := os.Open(j.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			continue
+		}
+		if filter == nil || filter.Matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if filter != nil && filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[len(entries)-filter.Limit:]
+	}
+	return entries, nil
+}
+
+func (f *Filter) Matches(e *Entry) bool {
+	if f.Env != "" && !strings.EqualFold(f.Env, e.Env) {
+		return false
+	}
+	if id := strings.Trim(f.ID, "/"); id != "" {
+		eid := strings.Trim(e.ID, "/")
+		return eid == id || strings.HasPrefix(eid, id+"/")
+	}
+	return true
+}
+
+// Returns the SHA-256 hash (hex) of {data}
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns the name of the current OS user
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,43 @@
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendAndQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	j := New(filepath.Join(dir, "nested"))
+	entries, err := j.Query(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.NoError(t, j.Append(&Entry{Env: "qa", ID: "/product/api", Action: "create", Outcome: OutcomeSuccess}))
+	assert.NoError(t, j.Append(&Entry{Env: "prod", ID: "/product/api", Action: "create", Outcome: OutcomeSuccess}))
+	assert.NoError(t, j.Append(&Entry{Env: "prod", ID: "/product-admin", Action: "scale", Outcome: OutcomeFailed, Error: "boom"}))
+	assert.NoError(t, j.Append(&Entry{Env: "prod", ID: "/product/web", Action: "destroy", Outcome: OutcomeSuccess}))
+
+	entries, err = j.Query(&Filter{ID: "/product", Env: "PROD"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "/product/api", entries[0].ID)
+	assert.Equal(t, "/product/web", entries[1].ID)
+	assert.NotEmpty(t, entries[0].User)
+	assert.False(t, entries[0].Timestamp.IsZero())
+
+	entries, err = j.Query(&Filter{Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "destroy", entries[0].Action)
+
+	info, err := os.Stat(j.Filename())
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,158 @@
+// Generates JSON schemas from Go types and validates decoded documents against them
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	Draft = "http://json-schema.org/draft-07/schema#"
+
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+
+	definitionsRef = "#/definitions/"
+)
+
+// A JSON schema (draft 07).  Only the keywords needed to describe descriptors are supported
+type Schema struct {
+	Schema      string        `json:"$schema,omitempty"`
+	Ref         string        `json:"$ref,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Type        string        `json:"type,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	// Set by the server and ignored when submitted
+	ReadOnly   bool               `json:"readOnly,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// Either false or the *Schema of any properties not declared within Properties
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Generates schemas from types using their JSON field names.  Every struct type becomes a definition
+// which does not allow additional properties.  Annotations are keyed by "TypeName.jsonField" (or
+// "TypeName" for the type itself)
+type Reflector struct {
+	Descriptions map[string]string
+	Enums        map[string][]interface{}
+	ReadOnly     map[string]bool
+	// Schemas used as is for types with custom JSON encoding instead of reflecting their fields
+	Types map[reflect.Type]*Schema
+}
+
+// Returns the schema of the type of {v} titled {title}
+func (r *Reflector) Reflect(title string, v interface{}) *Schema {
+	root := &Schema{Schema: Draft, Title: title, Definitions: map[string]*Schema{}}
+	ref := r.reflectType(root, reflect.TypeOf(v))
+	root.Ref = ref.Ref
+	if def, ok := root.Definitions[strings.TrimPrefix(ref.Ref, definitionsRef)]; ok {
+		root.Description = def.Description
+	}
+	return root
+}
+
+func (r *Reflector) reflectType(root *Schema, t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if s, ok := r.Types[t]; ok {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: TypeString}
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: TypeArray, Items: r.reflectType(root, t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: TypeObject, AdditionalProperties: r.reflectType(root, t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := root.Definitions[name]; !ok {
+			def := &Schema{Type: TypeObject, Description: r.Descriptions[name], Properties: map[string]*Schema{}, AdditionalProperties: false}
+			// registered before its fields are reflected so recursive types refer to themselves
+			root.Definitions[name] = def
+			r.reflectFields(root, def, t, name)
+		}
+		return &Schema{Ref: definitionsRef + name}
+	}
+	// interfaces and anything else accept any value
+	return &Schema{}
+}
+
+func (r *Reflector) reflectFields(root, def *Schema, t reflect.Type, typeName string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			et := f.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				r.reflectFields(root, def, et, typeName)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		key := typeName + "." + name
+		s := r.reflectType(root, f.Type)
+		if desc, enum, ro := r.Descriptions[key], r.Enums[key], r.ReadOnly[key]; desc != "" || enum != nil || ro {
+			if s.Ref != "" {
+				// keywords alongside $ref are ignored so the reference is wrapped
+				s = &Schema{OneOf: []*Schema{s}}
+			} else {
+				c := *s
+				s = &c
+			}
+			s.Description, s.ReadOnly = desc, ro
+			if enum != nil {
+				if s.Type == TypeArray && s.Items != nil {
+					items := *s.Items
+					items.Enum = enum
+					s.Items = &items
+				} else {
+					s.Enum = enum
+				}
+			}
+		}
+		def.Properties[name] = s
+	}
+}
+
+// Returns the definition referenced by {s} within {root} or {s} itself if it is not a reference
+func (root *Schema) resolve(s *Schema) *Schema {
+	if s.Ref == "" {
+		return s
+	}
+	if def, ok := root.Definitions[strings.TrimPrefix(s.Ref, definitionsRef)]; ok {
+		return def
+	}
+	return &Schema{}
+}
@@ -0,0 +1,72 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCheck struct {
+	Protocol string `json:"protocol,omitempty"`
+	Port     int    `json:"port"`
+}
+
+type testGroup struct {
+	ID     string            `json:"id"`
+	Checks []*testCheck      `json:"checks,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Groups []*testGroup      `json:"groups,omitempty"`
+	Hidden string            `json:"-"`
+}
+
+var testReflector = &Reflector{
+	Descriptions: map[string]string{"testGroup": "A group", "testGroup.id": "The id"},
+	Enums:        map[string][]interface{}{"testCheck.protocol": {"HTTP", "TCP"}},
+}
+
+func TestReflect(t *testing.T) {
+	s := testReflector.Reflect("Test", &testGroup{})
+
+	assert.Equal(t, Draft, s.Schema)
+	assert.Equal(t, "#/definitions/testGroup", s.Ref)
+	assert.Equal(t, "A group", s.Description)
+
+	group := s.Definitions["testGroup"]
+	assert.Equal(t, TypeObject, group.Type)
+	assert.Equal(t, false, group.AdditionalProperties)
+	assert.Equal(t, "The id", group.Properties["id"].Description)
+	assert.Equal(t, "#/definitions/testGroup", group.Properties["groups"].Items.Ref)
+	assert.Equal(t, TypeString, group.Properties["labels"].AdditionalProperties.(*Schema).Type)
+	assert.NotContains(t, group.Properties, "Hidden")
+
+	check := s.Definitions["testCheck"]
+	assert.Equal(t, TypeInteger, check.Properties["port"].Type)
+	assert.Equal(t, []interface{}{"HTTP", "TCP"}, check.Properties["protocol"].Enum)
+
+	_, err := json.Marshal(s)
+	assert.NoError(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	s := testReflector.Reflect("Test", &testGroup{})
+
+	var doc interface{}
+	json.Unmarshal([]byte(`{
+		"id": "/product",
+		"labels": {"tier": 1},
+		"groups": [{"id": "/product/api", "checks": [{"protocol": "HTTPS", "port": 8.5}], "owner": "team"}]
+	}`), &doc)
+
+	errs := s.Validate(doc)
+	assert.Len(t, errs, 4)
+	assert.Equal(t, "groups[0].checks[0].port", errs[0].Path())
+	assert.Equal(t, "must be of type integer", errs[0].Message)
+	assert.Equal(t, "groups[0].checks[0].protocol", errs[1].Path())
+	assert.Equal(t, "'HTTPS' must be one of HTTP, TCP", errs[1].Message)
+	assert.Equal(t, "groups[0].owner: unknown field", errs[2].Error())
+	assert.Equal(t, "labels.tier", errs[3].Path())
+
+	json.Unmarshal([]byte(`{"id": "/product", "checks": [{"protocol": "TCP", "port": 80}], "groups": null}`), &doc)
+	assert.Empty(t, s.Validate(doc))
+}
@@ -0,0 +1,141 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// A value within a document which does not satisfy the schema
+type ValidationError struct {
+	// Path segments of the offending value.  Array indexes are appended to the preceding segment
+	// (eg. ["container", "portMappings[0]", "protocol"])
+	Keys    []string
+	Message string
+}
+
+// Returns the dotted path of the offending value
+func (e *ValidationError) Path() string {
+	return strings.Join(e.Keys, ".")
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Keys) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path(), e.Message)
+}
+
+// Validates the decoded JSON document {doc} (eg. from json.Unmarshal into an interface{}) against the
+// schema returning every violation found
+func (s *Schema) Validate(doc interface{}) []*ValidationError {
+	return s.validate(s, doc, []string{})
+}
+
+func (s *Schema) validate(root *Schema, v interface{}, keys []string) []*ValidationError {
+	s = root.resolve(s)
+	if v == nil {
+		// null decodes to the zero value of any type
+		return nil
+	}
+
+	if len(s.OneOf) > 0 {
+		var first []*ValidationError
+		for _, alt := range s.OneOf {
+			errs := alt.validate(root, v, keys)
+			if len(errs) == 0 {
+				return nil
+			}
+			if first == nil {
+				first = errs
+			}
+		}
+		if len(s.OneOf) == 1 {
+			return first
+		}
+		return []*ValidationError{{Keys: keys, Message: "does not match any of the allowed forms"}}
+	}
+
+	if s.Type != "" && !typeMatches(s.Type, v) {
+		return []*ValidationError{{Keys: keys, Message: fmt.Sprintf("must be of type %s", s.Type)}}
+	}
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		allowed := []string{}
+		for _, e := range s.Enum {
+			allowed = append(allowed, fmt.Sprint(e))
+		}
+		return []*ValidationError{{Keys: keys, Message: fmt.Sprintf("'%v' must be one of %s", v, strings.Join(allowed, ", "))}}
+	}
+
+	errs := []*ValidationError{}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, req := range s.Required {
+			if _, ok := val[req]; !ok {
+				errs = append(errs, &ValidationError{Keys: keys, Message: fmt.Sprintf("missing required field '%s'", req)})
+			}
+		}
+		names := make([]string, 0, len(val))
+		for k := range val {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			path := append(append([]string{}, keys...), k)
+			if p, ok := s.Properties[k]; ok {
+				errs = append(errs, p.validate(root, val[k], path)...)
+			} else if ap, ok := s.AdditionalProperties.(*Schema); ok {
+				errs = append(errs, ap.validate(root, val[k], path)...)
+			} else if ap, ok := s.AdditionalProperties.(bool); ok && !ap {
+				errs = append(errs, &ValidationError{Keys: path, Message: "unknown field"})
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range val {
+				path := append([]string{}, keys...)
+				if len(path) > 0 {
+					path[len(path)-1] = fmt.Sprintf("%s[%d]", path[len(path)-1], i)
+				} else {
+					path = []string{fmt.Sprintf("[%d]", i)}
+				}
+				errs = append(errs, s.Items.validate(root, item, path)...)
+			}
+		}
+	}
+	return errs
+}
+
+func typeMatches(t string, v interface{}) bool {
+	switch t {
+	case TypeObject:
+		_, ok := v.(map[string]interface{})
+		return ok
+	case TypeArray:
+		_, ok := v.([]interface{})
+		return ok
+	case TypeString:
+		_, ok := v.(string)
+		return ok
+	case TypeBoolean:
+		_, ok := v.(bool)
+		return ok
+	case TypeNumber:
+		_, ok := v.(float64)
+		return ok
+	case TypeInteger:
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	}
+	return true
+}
+
+func inEnum(v interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}